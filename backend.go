@@ -0,0 +1,260 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Backend is everything sup needs from a code host. The TUI only ever talks
+// to hosts through backendFor, so tests and --demo can swap in fakeBackend
+// (via newBackend) and drive model.Update without the network or gh.
+type Backend interface {
+	// SearchPRs returns one page of results for a GitHub search query.
+	// after is the cursor from the previous page, or "" for the first page.
+	SearchPRs(query, after string) (prPage, error)
+	// FetchPR returns the current state of a single PR. The Repository field
+	// of the result may be empty; callers fill it in from what they asked for.
+	FetchPR(owner, repo string, number int) (PR, error)
+	// FetchPRs refreshes several PRs at once. It returns the PRs it could
+	// fetch, with Host and Repository copied from the input, and an error
+	// describing any it couldn't.
+	FetchPRs(prs []PR) ([]PR, error)
+	// FetchPRDetail returns the extra fields shown in the detail pane.
+	FetchPRDetail(pr PR) (PRDetail, error)
+	FetchDiff(pr PR) ([]byte, error)
+	// SubmitReview posts a review. action is "approve", "request-changes"
+	// or "comment"; body is ignored for approvals.
+	SubmitReview(pr PR, action, body string) error
+	// MergeInfo reports the repo's allowed merge strategies and whether the
+	// PR can be merged right now.
+	MergeInfo(pr PR) (mergeInfo, error)
+	MergePR(pr PR, opts mergeOptions) error
+	// BranchPRs finds the newest PR for each head branch in a repo, keyed by
+	// branch, with HeadRefOid and HeadRepository set. Branches no PR was
+	// opened from are left out.
+	BranchPRs(owner, repo string, branches []string) (map[string]PR, error)
+	CurrentUser() (string, error)
+	Orgs() ([]string, error)
+}
+
+type prPage struct {
+	PRs       []PR
+	EndCursor string
+	HasNext   bool
+}
+
+// fakeReview records a SubmitReview call against fakeBackend.
+type fakeReview struct {
+	PR     string // prKey of the reviewed PR
+	Action string
+	Body   string
+}
+
+// fakeMerge records a MergePR call against fakeBackend.
+type fakeMerge struct {
+	PR   string
+	Opts mergeOptions
+}
+
+// fakeBackend is an in-memory Backend. It serves --demo and lets tests
+// script PR lists, diffs and failures without touching GitHub.
+type fakeBackend struct {
+	mu       sync.Mutex
+	prs      []PR
+	user     string
+	orgs     []string
+	diffs    map[string][]byte   // keyed by prKey
+	details  map[string]PRDetail // keyed by prKey; missing entries are synthesised
+	reviews  []fakeReview
+	merges   []fakeMerge
+	pageSize int
+	err      error // when set, every call fails with it
+}
+
+func newFakeBackend(prs []PR) *fakeBackend {
+	return &fakeBackend{prs: prs, diffs: map[string][]byte{}, details: map[string]PRDetail{}, pageSize: 50}
+}
+
+func (f *fakeBackend) SearchPRs(query, after string) (prPage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return prPage{}, f.err
+	}
+
+	// Honour org: qualifiers so multi-shard setups don't return duplicates;
+	// everything else in the query is ignored.
+	var org string
+	for _, term := range strings.Fields(query) {
+		if strings.HasPrefix(term, "org:") {
+			org = strings.TrimPrefix(term, "org:")
+		}
+	}
+	var matched []PR
+	for _, pr := range f.prs {
+		if org == "" || strings.EqualFold(pr.Repository.Owner.Login, org) {
+			matched = append(matched, pr)
+		}
+	}
+
+	start := 0
+	if after != "" {
+		n, err := strconv.Atoi(after)
+		if err != nil {
+			return prPage{}, fmt.Errorf("bad cursor %q", after)
+		}
+		start = n
+	}
+	if start > len(matched) {
+		start = len(matched)
+	}
+	end := start + f.pageSize
+	if f.pageSize <= 0 || end > len(matched) {
+		end = len(matched)
+	}
+	page := prPage{PRs: append([]PR(nil), matched[start:end]...)}
+	if end < len(matched) {
+		page.HasNext = true
+		page.EndCursor = strconv.Itoa(end)
+	}
+	return page, nil
+}
+
+func (f *fakeBackend) FetchPR(owner, repo string, number int) (PR, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return PR{}, f.err
+	}
+	for _, pr := range f.prs {
+		if pr.Repository.Owner.Login == owner && pr.Repository.Name == repo && pr.Number == number {
+			return pr, nil
+		}
+	}
+	return PR{}, fmt.Errorf("PR %s/%s#%d not found", owner, repo, number)
+}
+
+func (f *fakeBackend) FetchPRs(prs []PR) ([]PR, error) {
+	var out []PR
+	var firstErr error
+	for _, pr := range prs {
+		updated, err := f.FetchPR(pr.Repository.Owner.Login, pr.Repository.Name, pr.Number)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		updated.Host = pr.Host
+		updated.Repository = pr.Repository
+		out = append(out, updated)
+	}
+	return out, firstErr
+}
+
+func (f *fakeBackend) FetchPRDetail(pr PR) (PRDetail, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return PRDetail{}, f.err
+	}
+	if d, ok := f.details[prKey(pr)]; ok {
+		return d, nil
+	}
+	return PRDetail{BaseRefName: "main", HeadRefName: pr.HeadRefName}, nil
+}
+
+func (f *fakeBackend) FetchDiff(pr PR) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	if d, ok := f.diffs[prKey(pr)]; ok {
+		return d, nil
+	}
+	return nil, fmt.Errorf("no diff for %s", prKey(pr))
+}
+
+func (f *fakeBackend) SubmitReview(pr PR, action, body string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	f.reviews = append(f.reviews, fakeReview{PR: prKey(pr), Action: action, Body: body})
+	for i := range f.prs {
+		if prKey(f.prs[i]) != prKey(pr) {
+			continue
+		}
+		switch action {
+		case "approve":
+			f.prs[i].ReviewDecision = "APPROVED"
+		case "request-changes":
+			f.prs[i].ReviewDecision = "CHANGES_REQUESTED"
+		}
+	}
+	return nil
+}
+
+func (f *fakeBackend) MergeInfo(pr PR) (mergeInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return mergeInfo{}, f.err
+	}
+	return mergeInfo{
+		PullRequestID:            prKey(pr),
+		HeadRefID:                pr.HeadRefName,
+		Methods:                  []string{"MERGE", "SQUASH", "REBASE"},
+		Mergeable:                "MERGEABLE",
+		MergeStateStatus:         "CLEAN",
+		AutoMergeAllowed:         true,
+		ViewerCanEnableAutoMerge: true,
+	}, nil
+}
+
+func (f *fakeBackend) MergePR(pr PR, opts mergeOptions) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	f.merges = append(f.merges, fakeMerge{PR: prKey(pr), Opts: opts})
+	return nil
+}
+
+func (f *fakeBackend) BranchPRs(owner, repo string, branches []string) (map[string]PR, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	out := map[string]PR{}
+	for _, b := range branches {
+		for _, pr := range f.prs {
+			if pr.Repository.Owner.Login == owner && pr.Repository.Name == repo && pr.HeadRefName == b {
+				if pr.HeadRepository == nil {
+					// Unless scripted otherwise, PRs come from the repo itself.
+					pr.HeadRepository = &pr.Repository
+				}
+				out[b] = pr
+			}
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeBackend) CurrentUser() (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.user, f.err
+}
+
+func (f *fakeBackend) Orgs() ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.orgs, f.err
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// checkoutPR checks a PR out locally — in the main clone, or in a new
+// worktree when worktree is set — or finds the worktree that already has
+// its branch, and writes the path for the shell wrapper to cd into.
+func checkoutPR(pr PR, worktree bool) error {
+	repoPath := findRepoPath(prHost(pr), pr.Repository.Owner.Login, pr.Repository.Name)
+	if repoPath == "" {
+		notFound := fmt.Errorf("no clone of %s found in your repo roots (sup repos lists what was found).\nClone it: gh repo clone %s, or sup checkout --clone partial\nOr set repo_roots, repo_depth or [repos] in %s, or SUP_DEV_DIR (--dev-dir)",
+			repoSlug(pr), repoSlug(pr), configPath())
+		path, err := offerClone(pr, notFound)
+		if err != nil {
+			return err
+		}
+		repoPath = path
+	}
+
+	// Check if the branch is already checked out in a worktree
+	if wtPath := findWorktreePath(repoPath, pr.HeadRefName); wtPath != "" {
+		fmt.Printf("Branch '%s' already checked out at %s\n", pr.HeadRefName, wtPath)
+		return os.WriteFile(selectionFile, []byte(wtPath), 0644)
+	}
+
+	// gh pr checkout --force would throw uncommitted changes away, so look
+	// first. A new worktree leaves them alone, but is only added beside them
+	// when --on-dirty says so, so work in progress doesn't go unnoticed.
+	changes, err := uncommittedChanges(repoPath, false)
+	if err != nil {
+		return err
+	}
+	carry := false
+	if len(changes) > 0 {
+		action := onDirty
+		if !worktree {
+			action = dirtyAction(repoPath, pr, changes)
+		}
+		switch action {
+		case "stash":
+			if worktree {
+				return fmt.Errorf("--on-dirty stash doesn't apply to a new worktree, which leaves the changes in %s alone; pass --on-dirty carry to add it anyway", repoPath)
+			}
+			restore, err := stashForCheckout(repoPath, pr)
+			if err != nil {
+				return err
+			}
+			// Repeated last, where the checkout's output won't bury it.
+			defer fmt.Print(restore)
+		case "carry":
+			carry = true // a new worktree leaves them where they are
+		case "worktree":
+			worktree = true
+		default:
+			if worktree {
+				return fmt.Errorf("%s has %d uncommitted change(s); commit or stash them first, or pass --on-dirty carry to add the worktree anyway", repoPath, len(changes))
+			}
+			return fmt.Errorf("checkout of PR #%d aborted: %s has uncommitted changes (--on-dirty stash, carry or worktree skips the question)", pr.Number, repoPath)
+		}
+	}
+
+	targetPath := repoPath
+	if worktree {
+		wtPath, err := addPRWorktree(repoPath, pr)
+		if err != nil {
+			return err
+		}
+		targetPath = wtPath
+	} else {
+		fmt.Printf("Checking out PR #%d in %s...\n", pr.Number, repoPath)
+		args := []string{"pr", "checkout", fmt.Sprintf("%d", pr.Number)}
+		if !carry {
+			// The tree is clean by now, so --force only resets a stale
+			// local copy of the PR branch.
+			args = append(args, "--force")
+		}
+		cmd := exec.Command("gh", args...)
+		cmd.Dir = repoPath
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			if carry {
+				return fmt.Errorf("gh pr checkout failed: %v (your changes probably conflict with the PR; they're untouched)", err)
+			}
+			return fmt.Errorf("gh pr checkout failed: %v", err)
+		}
+	}
+
+	// Write path for shell wrapper to cd into
+	return os.WriteFile(selectionFile, []byte(targetPath), 0644)
+}
+
+// dirtyActions are the answers to uncommitted changes in the clone a PR is
+// about to be checked out in, in prompt order (abort is the default).
+var dirtyActions = []choice{
+	{"a", "abort", "leave everything as it is"},
+	{"s", "stash", "git stash them, to restore later"},
+	{"c", "carry", "bring them along to the PR's branch"},
+	{"w", "worktree", "check the PR out in a new worktree instead"},
+}
+
+// onDirty is --on-dirty: what to do about uncommitted changes without
+// asking. --allow-dirty sets it to carry.
+var onDirty string
+
+func validDirtyAction(action string) bool {
+	for _, a := range dirtyActions {
+		if a.name == action {
+			return true
+		}
+	}
+	return false
+}
+
+func dirtyActionNames() []string {
+	names := make([]string, len(dirtyActions))
+	for i, a := range dirtyActions {
+		names[i] = a.name
+	}
+	return names
+}
+
+// dirtyAction decides what to do about uncommitted changes in repoPath:
+// --on-dirty, or what the user picks after seeing them. Without a terminal
+// it aborts.
+func dirtyAction(repoPath string, pr PR, changes []string) string {
+	if onDirty != "" {
+		return onDirty
+	}
+	fmt.Printf("%s has uncommitted changes:\n", shortPath(repoPath))
+	for i, c := range changes {
+		if i == 10 {
+			fmt.Printf("  ... and %d more\n", len(changes)-i)
+			break
+		}
+		fmt.Println("  " + c)
+	}
+	return askChoice(fmt.Sprintf("Checking out PR #%d would overwrite them. What now?", pr.Number), dirtyActions)
+}
+
+// stashForCheckout stashes the changes in repoPath with a message naming
+// the PR, and returns how to get them back.
+func stashForCheckout(repoPath string, pr PR) (string, error) {
+	var back string // what to check out again before popping
+	if out, err := runGit(repoPath, "branch", "--show-current"); err == nil {
+		back = strings.TrimSpace(string(out))
+	}
+	if back == "" { // detached
+		out, err := runGit(repoPath, "rev-parse", "--short", "HEAD")
+		if err != nil {
+			return "", err
+		}
+		back = strings.TrimSpace(string(out))
+	}
+	msg := fmt.Sprintf("sup: %s before checking out %s", back, prRef(pr))
+	if _, err := runGit(repoPath, "stash", "push", "-m", msg); err != nil {
+		return "", err
+	}
+	fmt.Printf("Stashed your changes: %q\n", msg)
+	return fmt.Sprintf("Your changes on %s are stashed as %q. To restore them:\n  cd %s && git checkout %s && git stash pop\n(git stash list shows it; pop the right stash@{N} if you've stashed since.)\n",
+		back, msg, shortPath(repoPath), back), nil
+}
+
+var (
+	prURLPattern = regexp.MustCompile(`^(?:https?://)?([^/]+)/([^/]+)/([^/]+)/pulls?/(\d+)`)
+	prRefPattern = regexp.MustCompile(`^(?:([^/#]+)/)?([^/#]+)/([^/#]+)#(\d+)$`)
+)
+
+// parsePRRef accepts OWNER/REPO#N, HOST/OWNER/REPO#N or a PR URL and
+// returns a PR with just enough filled in to fetch the rest.
+func parsePRRef(ref string) (PR, error) {
+	var pr PR
+	var host, num string
+	if m := prURLPattern.FindStringSubmatch(ref); m != nil {
+		host, pr.Repository.Owner.Login, pr.Repository.Name, num = m[1], m[2], m[3], m[4]
+	} else if m := prRefPattern.FindStringSubmatch(ref); m != nil {
+		host, pr.Repository.Owner.Login, pr.Repository.Name, num = m[1], m[2], m[3], m[4]
+	} else {
+		return PR{}, fmt.Errorf("can't parse PR %q (want OWNER/REPO#NUMBER or a PR URL)", ref)
+	}
+	pr.Number, _ = strconv.Atoi(num)
+	host = normalizeHost(host)
+	if host == "" {
+		host = hostForOrg(pr.Repository.Owner.Login)
+	}
+	if host != githubDotCom {
+		pr.Host = host
+	}
+	return pr, nil
+}
+
+// fetchPRRef resolves a PR reference against its host.
+func fetchPRRef(ref string) (PR, error) {
+	stub, err := parsePRRef(strings.TrimSpace(ref))
+	if err != nil {
+		return PR{}, err
+	}
+	pr, err := backendFor(prHost(stub)).FetchPR(stub.Repository.Owner.Login, stub.Repository.Name, stub.Number)
+	if err != nil {
+		return PR{}, err
+	}
+	pr.Host = stub.Host
+	pr.Repository = stub.Repository
+	return pr, nil
+}
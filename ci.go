@@ -0,0 +1,152 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// checkContext is one entry of a commit's statusCheckRollup. GitHub returns a
+// union of CheckRun (Actions and other apps) and StatusContext (legacy commit
+// statuses); only the fields for the node's __typename are populated.
+type checkContext struct {
+	Typename   string `json:"__typename"`
+	Name       string `json:"name,omitempty"`       // CheckRun
+	Status     string `json:"status,omitempty"`     // CheckRun: QUEUED, IN_PROGRESS, COMPLETED...
+	Conclusion string `json:"conclusion,omitempty"` // CheckRun: SUCCESS, FAILURE, TIMED_OUT...
+	DetailsURL string `json:"detailsUrl,omitempty"` // CheckRun
+	Context    string `json:"context,omitempty"`    // StatusContext
+	State      string `json:"state,omitempty"`      // StatusContext: SUCCESS, FAILURE, ERROR, PENDING...
+	TargetURL  string `json:"targetUrl,omitempty"`  // StatusContext
+}
+
+type checkRollup struct {
+	State    string `json:"state"` // SUCCESS, FAILURE, ERROR, PENDING, EXPECTED
+	Contexts struct {
+		Nodes []checkContext `json:"nodes"`
+	} `json:"contexts"`
+}
+
+func (c checkContext) name() string {
+	if c.Typename == "StatusContext" {
+		return c.Context
+	}
+	return c.Name
+}
+
+func (c checkContext) url() string {
+	if c.Typename == "StatusContext" {
+		return c.TargetURL
+	}
+	return c.DetailsURL
+}
+
+func (c checkContext) failed() bool {
+	if c.Typename == "StatusContext" {
+		return c.State == "FAILURE" || c.State == "ERROR"
+	}
+	switch c.Conclusion {
+	case "FAILURE", "TIMED_OUT", "CANCELLED", "ACTION_REQUIRED", "STARTUP_FAILURE":
+		return true
+	}
+	return false
+}
+
+// statusCheckRollup returns the rollup of the PR's head commit, or nil when
+// the PR has no checks (or was cached before CI was fetched).
+func statusCheckRollup(pr PR) *checkRollup {
+	if len(pr.Commits.Nodes) == 0 {
+		return nil
+	}
+	return pr.Commits.Nodes[0].Commit.StatusCheckRollup
+}
+
+// ciState maps the rollup to the filter vocabulary: "passing", "failing",
+// "pending", or "" when there are no checks.
+func ciState(pr PR) string {
+	rollup := statusCheckRollup(pr)
+	if rollup == nil {
+		return ""
+	}
+	switch rollup.State {
+	case "SUCCESS":
+		return "passing"
+	case "FAILURE", "ERROR":
+		return "failing"
+	case "PENDING", "EXPECTED":
+		return "pending"
+	}
+	return ""
+}
+
+func ciSymbol(pr PR) string {
+	switch ciState(pr) {
+	case "passing":
+		return "✓"
+	case "failing":
+		return "✗"
+	case "pending":
+		return "●"
+	}
+	return " "
+}
+
+func ciStyle(pr PR, selected bool) lipgloss.Style {
+	switch ciState(pr) {
+	case "passing":
+		if selected {
+			return selectedApprovedStyle
+		}
+		return approvedStyle
+	case "failing":
+		if selected {
+			return selectedChangesRequestedStyle
+		}
+		return changesRequestedStyle
+	case "pending":
+		if selected {
+			return selectedReviewRequestedStyle
+		}
+		return reviewRequestedStyle
+	}
+	return normalStyle
+}
+
+func failingChecks(pr PR) []checkContext {
+	rollup := statusCheckRollup(pr)
+	if rollup == nil {
+		return nil
+	}
+	var failed []checkContext
+	for _, c := range rollup.Contexts.Nodes {
+		if c.failed() {
+			failed = append(failed, c)
+		}
+	}
+	return failed
+}
+
+func (m model) checksView() string {
+	var s strings.Builder
+
+	pr := m.checksPR
+	s.WriteString("\n  " + titleStyle.Render(truncate("Failing checks — "+prKey(*pr)+" "+pr.Title, m.width-4)) + "\n\n")
+	failed := failingChecks(*pr)
+	switch {
+	case len(failed) > 0:
+		for _, c := range failed {
+			s.WriteString("  " + changesRequestedStyle.Render("✗ "+c.name()) + "\n")
+			if u := c.url(); u != "" {
+				s.WriteString("    " + dimStyle.Render(truncate(u, m.width-6)) + "\n")
+			}
+		}
+	case ciState(*pr) == "pending":
+		s.WriteString("  " + reviewRequestedStyle.Render("● Checks still running, nothing has failed yet") + "\n")
+	case ciState(*pr) == "passing":
+		s.WriteString("  " + approvedStyle.Render("✓ All checks passing") + "\n")
+	default:
+		s.WriteString("  No checks reported for this PR.\n")
+	}
+	s.WriteString("\n  " + dimStyle.Render(closeHint("checks")) + "\n")
+	return s.String()
+}
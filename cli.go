@@ -0,0 +1,682 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// version is stamped by goreleaser (-X main.version=...). `go install`
+// builds fall back to the module version from the build info.
+var version = "dev"
+
+func versionString() string {
+	if version == "dev" {
+		if bi, ok := debug.ReadBuildInfo(); ok && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+			return bi.Main.Version
+		}
+	}
+	return version
+}
+
+// opts holds the settings that can come from a flag, an environment
+// variable or the config file. Flags win over the environment, which wins
+// over the file.
+var opts struct {
+	Org        string // --org, SUP_ORG, orgs: comma-separated orgs, skips detection
+	Host       string // --host, SUP_HOST, host: default GitHub host
+	Hosts      string // --hosts, SUP_HOSTS, hosts: org=host pairs
+	DevDir     string // --dev-dir, SUP_DEV_DIR: where to look for repos first
+	View       string // --view, SUP_VIEW, default_view: the TUI's first view
+	Editor     string // SUP_EDITOR, editor: review body editor
+	DiffViewer string // SUP_DIFF_VIEWER, diff_viewer: command the d key pipes diffs to
+	Theme      string // --theme, SUP_THEME, theme: color theme
+	Checkout   string // --checkout, SUP_CHECKOUT, checkout: branch or worktree
+}
+
+// loadOptions fills opts from the config file, then the environment.
+func loadOptions() {
+	env := func(name, fallback string) string {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+		return fallback
+	}
+	hosts := make([]string, 0, len(cfg.Hosts))
+	for org, host := range cfg.Hosts {
+		hosts = append(hosts, org+"="+host)
+	}
+	sort.Strings(hosts)
+	opts.Org = env("SUP_ORG", strings.Join(cfg.Orgs, ","))
+	opts.Host = env("SUP_HOST", cfg.Host)
+	opts.Hosts = env("SUP_HOSTS", strings.Join(hosts, ","))
+	opts.DevDir = os.Getenv("SUP_DEV_DIR")
+	opts.View = env("SUP_VIEW", cfg.DefaultView)
+	opts.Editor = env("SUP_EDITOR", cfg.Editor)
+	opts.DiffViewer = env("SUP_DIFF_VIEWER", cfg.DiffViewer)
+	opts.Theme = env("SUP_THEME", cfg.Theme)
+	opts.Checkout = env("SUP_CHECKOUT", cfg.Checkout)
+}
+
+// addCommonFlags registers the flags every command accepts.
+func addCommonFlags(fs *flag.FlagSet) {
+	fs.StringVar(&opts.Org, "org", opts.Org, "comma-separated orgs to show, skipping detection (env SUP_ORG)")
+	fs.StringVar(&opts.Host, "host", opts.Host, "GitHub host, e.g. a GHES instance (env SUP_HOST)")
+	fs.StringVar(&opts.Hosts, "hosts", opts.Hosts, "per-org hosts as org=host,... (env SUP_HOSTS)")
+	fs.StringVar(&opts.DevDir, "dev-dir", opts.DevDir, "directory to search for repos first (env SUP_DEV_DIR)")
+	fs.BoolVar(&mineMode, "mine", mineMode, "show PRs involving you instead of your orgs")
+	fs.BoolVar(&mineMode, "m", mineMode, "shorthand for --mine")
+	fs.BoolVar(&demoMode, "demo", demoMode, "use mock data (for screenshots)")
+}
+
+type command struct {
+	name    string
+	args    string // synopsis of positional arguments
+	summary string
+	// setup registers the command's own flags and returns the function that
+	// runs it once flags are parsed. It receives the positional arguments.
+	setup func(fs *flag.FlagSet) func(args []string) int
+}
+
+var commands []*command
+
+func init() {
+	// Registered here rather than in the var declaration: the help command
+	// reads the table, which would otherwise be an initialization cycle.
+	commands = []*command{
+		{name: "tui", summary: "Browse PRs interactively and check one out (default)", setup: setupTUI},
+		{name: "list", args: "[filter...]", summary: "Print PRs for scripts: plain, --json, --tsv or --template", setup: setupList},
+		{name: "checkout", args: "<owner/repo#number | url>", summary: "Check out a PR without the picker", setup: setupCheckout},
+		{name: "worktrees", args: "[--prune [--dry-run] [--force] [path...]]", summary: "List PR worktrees and prune merged or closed ones", setup: setupWorktrees},
+		{name: "review", args: "<owner/repo#number | url>", summary: "Approve, comment on, or request changes on a PR", setup: setupReview},
+		{name: "repos", args: "[--refresh] [[host/]owner/repo]", summary: "List the local clones sup knows, or print one's path", setup: setupRepos},
+		{name: "cache", args: "<path | clear>", summary: "Show or clear sup's caches", setup: setupCache},
+		{name: "config", args: "[show | init | check | path]", summary: "Show, create or check the config file", setup: setupConfig},
+		{name: "version", summary: "Print the version", setup: setupVersion},
+		{name: "help", args: "[command]", summary: "Show help for a command", setup: setupHelp},
+	}
+}
+
+func findCommand(name string) *command {
+	for _, c := range commands {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// runCLI dispatches to a subcommand. Anything that isn't a known command
+// name — including no arguments or a bare flag like --mine — runs the TUI.
+func runCLI(args []string) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "-h", "-help", "--help":
+			printUsage(os.Stdout)
+			return 0
+		case "-v", "-version", "--version":
+			fmt.Println("sup " + versionString())
+			return 0
+		}
+	}
+
+	// A broken config file stops everything but `sup config`, which is
+	// how it gets checked and fixed.
+	if err := loadConfig(); err != nil {
+		if len(args) == 0 || args[0] != "config" {
+			fmt.Fprintf(os.Stderr, "sup: %v\n", err)
+			fmt.Fprintln(os.Stderr, "Run 'sup config check' after fixing it.")
+			return 2
+		}
+		configErr = err
+	}
+	loadOptions()
+
+	cmd := findCommand("tui")
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		if cmd = findCommand(args[0]); cmd == nil {
+			fmt.Fprintf(os.Stderr, "sup: unknown command %q\n\n", args[0])
+			printUsage(os.Stderr)
+			return 2
+		}
+		args = args[1:]
+	}
+	return runCommand(cmd, args)
+}
+
+func newFlagSet(cmd *command) (*flag.FlagSet, func([]string) int) {
+	fs := flag.NewFlagSet("sup "+cmd.name, flag.ContinueOnError)
+	run := cmd.setup(fs)
+	addCommonFlags(fs)
+	fs.Usage = func() { printCommandUsage(fs.Output(), cmd, fs) }
+	return fs, run
+}
+
+func runCommand(cmd *command, args []string) int {
+	fs, run := newFlagSet(cmd)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	initViews()
+	return run(fs.Args())
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintf(w, "sup %s — browse and check out GitHub PRs\n\n", versionString())
+	fmt.Fprintln(w, "Usage:\n  sup [command] [flags] [args]")
+	fmt.Fprintln(w, "\nCommands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(w, "\nRun 'sup help <command>' for a command's flags.")
+}
+
+func printCommandUsage(w io.Writer, cmd *command, fs *flag.FlagSet) {
+	synopsis := "sup " + cmd.name + " [flags]"
+	if cmd.args != "" {
+		synopsis += " " + cmd.args
+	}
+	fmt.Fprintf(w, "%s\n\nUsage:\n  %s\n\nFlags:\n", cmd.summary, synopsis)
+	fs.SetOutput(w)
+	fs.PrintDefaults()
+}
+
+// addCheckoutFlags registers the flags of the commands that check PRs out.
+func addCheckoutFlags(fs *flag.FlagSet) {
+	fs.StringVar(&opts.Checkout, "checkout", opts.Checkout, "check PRs out on a branch in the clone or in a new worktree: "+strings.Join(checkoutModes, ", ")+" (env SUP_CHECKOUT)")
+	fs.BoolVar(&allowDirty, "allow-dirty", allowDirty, "same as --on-dirty carry")
+	fs.StringVar(&cloneMode, "clone", cloneMode, "clone a missing repo without asking: "+strings.Join(cloneModeNames(), ", "))
+	fs.StringVar(&onDirty, "on-dirty", onDirty, "what to do about uncommitted changes without asking: "+strings.Join(dirtyActionNames(), ", "))
+}
+
+// checkCheckoutFlags validates the flags addCheckoutFlags registers.
+func checkCheckoutFlags() error {
+	if !validCheckoutMode(checkoutMode()) {
+		return fmt.Errorf("unknown checkout mode %q (want %s)", opts.Checkout, strings.Join(checkoutModes, " or "))
+	}
+	if cloneMode != "" && !validCloneMode(cloneMode) {
+		return fmt.Errorf("unknown clone mode %q (want %s)", cloneMode, strings.Join(cloneModeNames(), ", "))
+	}
+	if onDirty != "" && !validDirtyAction(onDirty) {
+		return fmt.Errorf("unknown --on-dirty action %q (want %s)", onDirty, strings.Join(dirtyActionNames(), ", "))
+	}
+	if allowDirty {
+		if onDirty != "" && onDirty != "carry" {
+			return fmt.Errorf("--allow-dirty is --on-dirty carry, so it can't be combined with --on-dirty %s", onDirty)
+		}
+		onDirty = "carry"
+	}
+	return nil
+}
+
+func setupTUI(fs *flag.FlagSet) func([]string) int {
+	fs.StringVar(&opts.View, "view", opts.View, "view to open on (env SUP_VIEW)")
+	fs.StringVar(&opts.Theme, "theme", opts.Theme, "color theme: "+strings.Join(themeNames(), ", ")+" (env SUP_THEME)")
+	addCheckoutFlags(fs)
+	return func(args []string) int {
+		if len(args) > 0 {
+			fmt.Fprintf(os.Stderr, "sup: unexpected argument %q\n", args[0])
+			return 2
+		}
+		if err := checkCheckoutFlags(); err != nil {
+			fmt.Fprintf(os.Stderr, "sup: %v\n", err)
+			return 2
+		}
+		if opts.View != "" && startView() < 0 {
+			fmt.Fprintf(os.Stderr, "sup: no view named %q\n", opts.View)
+			return 2
+		}
+		if err := setTheme(themeName()); err != nil {
+			fmt.Fprintf(os.Stderr, "sup: %v\n", err)
+			return 2
+		}
+		return runTUI()
+	}
+}
+
+func setupCheckout(fs *flag.FlagSet) func([]string) int {
+	addCheckoutFlags(fs)
+	return func(args []string) int {
+		if len(args) != 1 {
+			fmt.Fprintln(os.Stderr, "Usage: sup checkout [--checkout branch|worktree] [--clone MODE] [--on-dirty ACTION] <owner/repo#number | url>")
+			return 2
+		}
+		if err := checkCheckoutFlags(); err != nil {
+			fmt.Fprintf(os.Stderr, "sup: %v\n", err)
+			return 2
+		}
+		if err := initSession(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		pr, err := fetchPRRef(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		if err := checkoutPR(pr, checkoutMode() == "worktree"); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+}
+
+func setupWorktrees(fs *flag.FlagSet) func([]string) int {
+	prune := fs.Bool("prune", false, "remove worktrees and their local branches: the given paths, or every merged or closed one")
+	dryRun := fs.Bool("dry-run", false, "with --prune: show what would be removed")
+	fs.BoolVar(dryRun, "n", false, "shorthand for --dry-run")
+	force := fs.Bool("force", false, "with --prune: remove worktrees with uncommitted changes too")
+	return func(args []string) int {
+		if len(args) > 0 && !*prune {
+			fmt.Fprintln(os.Stderr, "sup worktrees: paths are only taken with --prune")
+			return 2
+		}
+		if err := initSession(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		wts := collectWorktrees()
+		if !*prune {
+			if len(wts) == 0 {
+				fmt.Println("No linked worktrees in your repos.")
+				return 0
+			}
+			tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			for _, w := range wts {
+				where := shortPath(w.Path)
+				if notes := w.notes(); notes != "" {
+					where += " · " + notes
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\n", w.state(), w.label(), where)
+			}
+			tw.Flush()
+			return 0
+		}
+
+		// Pick the worktrees to prune: the paths given, or every stale one.
+		var picked []prWorktree
+		if len(args) > 0 {
+			byPath := map[string]prWorktree{}
+			for _, w := range wts {
+				byPath[w.Path] = w
+			}
+			for _, arg := range args {
+				path, _ := filepath.Abs(expandPath(arg))
+				if real, err := filepath.EvalSymlinks(path); err == nil {
+					path = real
+				}
+				w, ok := byPath[path]
+				if !ok {
+					fmt.Fprintf(os.Stderr, "sup worktrees: %s is not a linked worktree of a known repo\n", arg)
+					return 1
+				}
+				picked = append(picked, w)
+			}
+		} else {
+			for _, w := range wts {
+				if w.stale() {
+					picked = append(picked, w)
+				}
+			}
+		}
+
+		status := 0
+		removed := 0
+		for _, w := range picked {
+			if !w.removable(*force) {
+				fmt.Fprintf(os.Stderr, "Skipping %s: %s (--force removes it anyway)\n", shortPath(w.Path), w.notes())
+				status = 1
+				continue
+			}
+			if *dryRun {
+				line := fmt.Sprintf("Would remove %s (%s, %s)", shortPath(w.Path), w.label(), w.state())
+				if w.Branch != "" {
+					line += " and git branch " + w.branchDeleteFlag() + " " + w.Branch
+				}
+				fmt.Println(line)
+				continue
+			}
+			kept, err := pruneWorktree(w, *force)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				status = 1
+				continue
+			}
+			if kept != "" {
+				fmt.Printf("Removed %s (%s); %s\n", shortPath(w.Path), w.label(), kept)
+			} else {
+				fmt.Printf("Removed %s (%s)\n", shortPath(w.Path), w.label())
+			}
+			removed++
+		}
+		if len(picked) == 0 {
+			fmt.Println("Nothing to prune.")
+		} else if !*dryRun {
+			fmt.Printf("Pruned %d of %d worktree(s).\n", removed, len(picked))
+		}
+		return status
+	}
+}
+
+func setupReview(fs *flag.FlagSet) func([]string) int {
+	approve := fs.Bool("approve", false, "approve the PR")
+	requestChanges := fs.Bool("request-changes", false, "request changes (needs --body)")
+	comment := fs.Bool("comment", false, "leave a review comment (needs --body)")
+	body := fs.String("body", "", "review body; \"-\" reads it from stdin")
+	fs.StringVar(body, "b", "", "shorthand for --body")
+	return func(args []string) int {
+		if len(args) != 1 {
+			fmt.Fprintln(os.Stderr, "Usage: sup review [--approve | --request-changes | --comment] [--body TEXT] <owner/repo#number | url>")
+			return 2
+		}
+		var action string
+		n := 0
+		for flagSet, a := range map[*bool]string{approve: "approve", requestChanges: "request-changes", comment: "comment"} {
+			if *flagSet {
+				action = a
+				n++
+			}
+		}
+		if n != 1 {
+			fmt.Fprintln(os.Stderr, "Error: pass exactly one of --approve, --request-changes or --comment")
+			return 2
+		}
+		text := *body
+		if text == "-" {
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: reading body: %v\n", err)
+				return 1
+			}
+			text = string(data)
+		}
+		text = strings.TrimSpace(text)
+		if action != "approve" && text == "" {
+			fmt.Fprintf(os.Stderr, "Error: --%s needs a --body\n", action)
+			return 2
+		}
+
+		if err := initSession(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		pr, err := parsePRRef(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 2
+		}
+		if err := backendFor(prHost(pr)).SubmitReview(pr, action, text); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		fmt.Printf("✓ %s %s\n", map[string]string{
+			"approve":         "Approved",
+			"request-changes": "Requested changes on",
+			"comment":         "Commented on",
+		}[action], prKey(pr))
+		return 0
+	}
+}
+
+func setupRepos(fs *flag.FlagSet) func([]string) int {
+	refresh := fs.Bool("refresh", false, "rescan the repo roots instead of using the cached index")
+	return func(args []string) int {
+		if len(args) > 1 {
+			fmt.Fprintln(os.Stderr, "Usage: sup repos [--refresh] [[host/]owner/repo]")
+			return 2
+		}
+		idx := loadRepoIndex()
+		if *refresh {
+			idx = refreshRepoIndex()
+		}
+		if len(args) == 1 {
+			host, parts := defaultHost(), strings.Split(args[0], "/")
+			if len(parts) == 3 {
+				host, parts = normalizeHost(parts[0]), parts[1:]
+			}
+			if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+				fmt.Fprintln(os.Stderr, "Usage: sup repos [--refresh] [[host/]owner/repo]")
+				return 2
+			}
+			path := findRepoPath(host, parts[0], parts[1])
+			if path == "" {
+				fmt.Fprintf(os.Stderr, "sup repos: no clone of %s found\n", args[0])
+				return 1
+			}
+			fmt.Println(path)
+			return 0
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, c := range idx.Clones {
+			for _, r := range c.Remotes {
+				where := shortPath(c.Path)
+				if r.Name != "origin" {
+					where += " (" + r.Name + ")"
+				}
+				slug := r.Owner + "/" + r.Repo
+				if r.Host != githubDotCom {
+					slug = r.Host + "/" + slug
+				}
+				fmt.Fprintf(tw, "%s\t%s\n", slug, where)
+			}
+		}
+		tw.Flush()
+		roots := make([]string, len(idx.Roots))
+		for i, root := range idx.Roots {
+			roots[i] = shortPath(root)
+		}
+		fmt.Printf("%d clones under %s, indexed %s ago (--refresh to rescan)\n",
+			len(idx.Clones), strings.Join(roots, ", "), time.Since(idx.Built).Round(time.Second))
+		return 0
+	}
+}
+
+func setupCache(fs *flag.FlagSet) func([]string) int {
+	all := fs.Bool("all", false, "with clear: also forget cached gh tokens")
+	return func(args []string) int {
+		if len(args) != 1 {
+			fmt.Fprintln(os.Stderr, "Usage: sup cache <path | clear>")
+			return 2
+		}
+		switch args[0] {
+		case "path":
+			fmt.Println(cacheDir())
+			return 0
+		case "clear":
+			entries, err := os.ReadDir(cacheDir())
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return 1
+			}
+			removed := 0
+			for _, e := range entries {
+				name := e.Name()
+				if strings.HasPrefix(name, "token") && !*all {
+					continue
+				}
+				if err := os.RemoveAll(filepath.Join(cacheDir(), name)); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					return 1
+				}
+				removed++
+			}
+			fmt.Printf("Removed %d cache entries from %s\n", removed, cacheDir())
+			return 0
+		}
+		fmt.Fprintf(os.Stderr, "sup cache: unknown action %q (want path or clear)\n", args[0])
+		return 2
+	}
+}
+
+// configErr is the config file's load error, kept for `sup config` so it
+// can report it instead of refusing to run.
+var configErr error
+
+func setupConfig(fs *flag.FlagSet) func([]string) int {
+	force := fs.Bool("force", false, "with init: overwrite an existing config file")
+	return func(args []string) int {
+		action := "show"
+		if len(args) > 0 {
+			action = args[0]
+		}
+		switch action {
+		case "path":
+			fmt.Println(configPath())
+			return 0
+		case "init":
+			return configInit(*force)
+		case "check":
+			return configCheck()
+		case "show":
+			if configErr != nil {
+				fmt.Fprintf(os.Stderr, "sup: %v\n", configErr)
+				return 2
+			}
+			showConfig(fs)
+			return 0
+		}
+		fmt.Fprintf(os.Stderr, "sup config: unknown action %q (want show, init, check or path)\n", action)
+		return 2
+	}
+}
+
+func configInit(force bool) int {
+	path := configPath()
+	if _, err := os.Stat(path); err == nil && !force {
+		fmt.Fprintf(os.Stderr, "Error: %s already exists (use --force to overwrite)\n", path)
+		return 1
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if err := os.WriteFile(path, []byte(configTemplate), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Printf("Wrote %s\n", path)
+	return 0
+}
+
+// configCheck reports every error in the config file, then warnings about
+// paths and commands it names. Warnings alone don't fail the check.
+func configCheck() int {
+	path := configPath()
+	var cerr *configError
+	switch {
+	case errors.As(configErr, &cerr):
+		for _, err := range cerr.errs {
+			fmt.Printf("✗ %v\n", err)
+		}
+		fmt.Printf("%s: %d problem(s)\n", path, len(cerr.errs))
+		return 1
+	case configErr != nil:
+		fmt.Fprintf(os.Stderr, "Error: %v\n", configErr)
+		return 1
+	case !configLoaded:
+		fmt.Printf("No config file at %s (run 'sup config init' to create one)\n", path)
+		return 0
+	}
+	for _, w := range cfg.warnings() {
+		fmt.Printf("! %s\n", w)
+	}
+	fmt.Printf("✓ %s is valid\n", path)
+	return 0
+}
+
+func showConfig(fs *flag.FlagSet) {
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	show := func(name, value, env string, inFile bool) {
+		source := "default"
+		switch {
+		case set[name]:
+			source = "--" + name
+		case os.Getenv(env) != "":
+			source = "$" + env
+		case inFile:
+			source = "config"
+		}
+		if value == "" {
+			value = "-"
+		}
+		fmt.Printf("%-11s %-40s (%s)\n", name, value, source)
+	}
+	show("org", opts.Org, "SUP_ORG", len(cfg.Orgs) > 0)
+	show("host", defaultHost(), "SUP_HOST", cfg.Host != "")
+	show("hosts", opts.Hosts, "SUP_HOSTS", len(cfg.Hosts) > 0)
+	hosts := orgHosts()
+	orgNames := make([]string, 0, len(hosts))
+	for org := range hosts {
+		orgNames = append(orgNames, org)
+	}
+	sort.Strings(orgNames)
+	for _, org := range orgNames {
+		fmt.Printf("  %s → %s\n", org, hosts[org])
+	}
+	show("dev-dir", opts.DevDir, "SUP_DEV_DIR", false)
+	show("view", opts.View, "SUP_VIEW", cfg.DefaultView != "")
+	show("editor", editorCommand(), "SUP_EDITOR", cfg.Editor != "")
+	show("diff-viewer", diffViewerCommand(), "SUP_DIFF_VIEWER", cfg.DiffViewer != "")
+	show("theme", themeName(), "SUP_THEME", cfg.Theme != "")
+	show("checkout", checkoutMode(), "SUP_CHECKOUT", cfg.Checkout != "")
+	worktrees := cfg.WorktreeDir
+	if worktrees == "" {
+		worktrees = defaultWorktreeDir
+	}
+	fmt.Printf("%-11s %s\n", "worktrees", worktrees)
+	fmt.Printf("%-11s %s (depth %d)\n", "repo roots", strings.Join(indexRoots(), ", "), repoDepth())
+	repos := make([]string, 0, len(cfg.Repos))
+	for repo := range cfg.Repos {
+		repos = append(repos, repo)
+	}
+	sort.Strings(repos)
+	for _, repo := range repos {
+		fmt.Printf("  %s → %s\n", repo, expandPath(cfg.Repos[repo]))
+	}
+	fmt.Printf("%-11s %s\n", "cache", cacheDir())
+	config := configPath()
+	if !configLoaded {
+		config += " (not found)"
+	}
+	fmt.Printf("%-11s %s\n", "config", config)
+	for _, v := range cfg.Views {
+		fmt.Printf("  view %q\n", v.Name)
+	}
+}
+
+func setupVersion(fs *flag.FlagSet) func([]string) int {
+	return func(args []string) int {
+		fmt.Println("sup " + versionString())
+		return 0
+	}
+}
+
+func setupHelp(fs *flag.FlagSet) func([]string) int {
+	return func(args []string) int {
+		if len(args) == 0 {
+			printUsage(os.Stdout)
+			return 0
+		}
+		cmd := findCommand(args[0])
+		if cmd == nil {
+			fmt.Fprintf(os.Stderr, "sup: unknown command %q\n", args[0])
+			return 2
+		}
+		cfs, _ := newFlagSet(cmd)
+		printCommandUsage(os.Stdout, cmd, cfs)
+		return 0
+	}
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-isatty"
+)
+
+// Checking out a PR whose repo isn't cloned offers to clone it first, on
+// the command line after the picker exits and as a prompt inside the TUI.
+
+// cloneModes are the ways a missing repo can be cloned, in prompt order.
+var cloneModes = []struct {
+	key, name, help string
+	args            []string // git clone flags
+}{
+	{"p", "partial", "all history, file contents fetched as needed", []string{"--filter=blob:none"}},
+	{"s", "shallow", "latest commit only", []string{"--depth=1"}},
+	{"f", "full", "everything", nil},
+}
+
+// cloneMode is --clone: clone missing repos this way without asking.
+var cloneMode string
+
+func validCloneMode(mode string) bool {
+	for _, c := range cloneModes {
+		if c.name == mode {
+			return true
+		}
+	}
+	return false
+}
+
+func cloneModeNames() []string {
+	names := make([]string, len(cloneModes))
+	for i, c := range cloneModes {
+		names[i] = c.name
+	}
+	return names
+}
+
+// cloneRoot is where missing repos are cloned: clone_root from the config,
+// else the first repo root that exists.
+func cloneRoot() string {
+	if cfg.CloneRoot != "" {
+		return expandPath(cfg.CloneRoot)
+	}
+	roots := indexRoots()
+	for _, root := range roots {
+		if fi, err := os.Stat(root); err == nil && fi.IsDir() && root != os.Getenv("HOME") {
+			return root
+		}
+	}
+	return roots[0]
+}
+
+// clonePath is where pr's repo would be cloned: <root>/<repo>, or
+// <root>/<owner>/<repo> when another repo already has that name.
+func clonePath(pr PR) string {
+	path := filepath.Join(cloneRoot(), pr.Repository.Name)
+	if _, err := os.Stat(path); err == nil {
+		path = filepath.Join(cloneRoot(), pr.Repository.Owner.Login, pr.Repository.Name)
+	}
+	return path
+}
+
+// cloneCommand clones pr's repo with gh, which sets up the remotes (and an
+// upstream remote for forks) the way gh pr checkout expects.
+func cloneCommand(pr PR, mode, path string) *exec.Cmd {
+	args := []string{"repo", "clone", repoSlug(pr), path}
+	for _, c := range cloneModes {
+		if c.name == mode && len(c.args) > 0 {
+			args = append(append(args, "--"), c.args...)
+		}
+	}
+	return exec.Command("gh", args...)
+}
+
+// addClonedRepo adds a fresh clone to the repo index so lookups find it
+// without a rescan.
+func addClonedRepo(path string) {
+	idx := loadRepoIndex()
+	repoIndexMu.Lock()
+	defer repoIndexMu.Unlock()
+	idx.Clones = append(idx.Clones, indexedClone{Path: path, Remotes: readRemotes(path)})
+	if data, err := json.Marshal(idx); err == nil {
+		os.WriteFile(repoIndexPath(), data, 0644)
+	}
+}
+
+// offerClone clones pr's missing repo: the --clone mode if given, else
+// whichever the user picks at a prompt. Without a terminal to ask on it
+// returns notFound.
+func offerClone(pr PR, notFound error) (string, error) {
+	path := clonePath(pr)
+	mode := cloneMode
+	if mode == "" {
+		choices := make([]choice, 0, len(cloneModes)+1)
+		for _, c := range cloneModes {
+			choices = append(choices, choice{c.key, c.name, c.help})
+		}
+		choices = append(choices, choice{"n", "no", ""})
+		mode = askChoice(fmt.Sprintf("%s isn't cloned. Clone it into %s?", repoSlug(pr), shortPath(path)), choices)
+		if mode == "" || mode == "no" {
+			return "", notFound
+		}
+	}
+
+	fmt.Printf("Cloning %s into %s (%s)...\n", repoSlug(pr), path, mode)
+	cmd := cloneCommand(pr, mode, path)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("gh repo clone failed: %v", err)
+	}
+	addClonedRepo(path)
+	return path, nil
+}
+
+// choice is one answer to askChoice.
+type choice struct {
+	key, name, help string
+}
+
+// askChoice asks a question on the terminal and returns the name of the
+// choice picked by key or name. Enter picks the first. It returns "" when
+// stdin isn't a terminal or the answer matches nothing.
+func askChoice(question string, choices []choice) string {
+	if !isatty.IsTerminal(os.Stdin.Fd()) {
+		return ""
+	}
+	fmt.Println(question)
+	for i, c := range choices {
+		line := "  [" + c.key + "]" + strings.TrimPrefix(c.name, c.key)
+		if c.help != "" {
+			line += " — " + c.help
+		}
+		if i == 0 {
+			line += " (default)"
+		}
+		fmt.Println(line)
+	}
+	fmt.Print("> ")
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if answer == "" {
+		return choices[0].name
+	}
+	for _, c := range choices {
+		if answer == c.key || answer == c.name {
+			return c.name
+		}
+	}
+	return ""
+}
+
+// repoFoundMsg is where pr's repo is cloned, or "" if it isn't, for the
+// action that was waiting to know: "checkout", "checkout-worktree" or
+// "clone".
+type repoFoundMsg struct {
+	pr     PR
+	path   string
+	action string
+}
+
+// findRepo looks for pr's clone off the UI goroutine, as a lookup that
+// misses rescans the repo roots.
+func (m model) findRepo(pr PR, action string) (tea.Model, tea.Cmd) {
+	m.actionPending = true
+	m.pendingLabel = "Looking for a clone of " + repoSlug(pr) + "..."
+	find := func() tea.Msg {
+		return repoFoundMsg{pr: pr, path: findRepoPath(prHost(pr), pr.Repository.Owner.Login, pr.Repository.Name), action: action}
+	}
+	return m, tea.Batch(find, spinnerTick())
+}
+
+// checkout quits the TUI so runTUI checks pr out; action is "checkout" or
+// "checkout-worktree".
+func (m model) checkout(pr PR, action string) (tea.Model, tea.Cmd) {
+	m.selected = &pr
+	m.inWorktree = action == "checkout-worktree" || checkoutMode() == "worktree"
+	m.quitting = true
+	return m, tea.Quit
+}
+
+type cloneDoneMsg struct {
+	pr   PR
+	path string
+	then string // action to continue with: "checkout", "checkout-worktree" or ""
+	err  error
+}
+
+// cloneIndexedMsg is sent once a fresh clone is in the repo index.
+type cloneIndexedMsg cloneDoneMsg
+
+// indexClone adds a fresh clone to the repo index off the UI goroutine, as
+// it may load the index first.
+func (m model) indexClone(msg cloneDoneMsg) (tea.Model, tea.Cmd) {
+	m.actionPending = true
+	m.pendingLabel = "Indexing " + shortPath(msg.path) + "..."
+	index := func() tea.Msg {
+		addClonedRepo(msg.path)
+		return cloneIndexedMsg(msg)
+	}
+	return m, tea.Batch(index, spinnerTick())
+}
+
+// startClonePrompt opens the clone prompt for pr; then is the action to
+// continue with once it's cloned.
+func (m *model) startClonePrompt(pr PR, then string) {
+	m.confirmAction = "clone"
+	m.confirmPR = &pr
+	m.cloneThen = then
+}
+
+// handleCloneKey handles input while the clone prompt is showing. The
+// clone runs in the foreground so git's progress shows.
+func (m model) handleCloneKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	pr, then := *m.confirmPR, m.cloneThen
+	m.confirmAction = ""
+	m.confirmPR = nil
+	m.cloneThen = ""
+	for _, c := range cloneModes {
+		if msg.String() != c.key {
+			continue
+		}
+		path := clonePath(pr)
+		return m, tea.ExecProcess(cloneCommand(pr, c.name, path), func(err error) tea.Msg {
+			return cloneDoneMsg{pr: pr, path: path, then: then, err: err}
+		})
+	}
+	// Anything else cancels.
+	return m, nil
+}
+
+func (m model) clonePromptView() string {
+	opts := make([]string, len(cloneModes))
+	for i, c := range cloneModes {
+		opts[i] = "[" + c.key + "]" + c.name[1:]
+	}
+	return fmt.Sprintf("  Clone %s into %s: %s · esc cancel", repoSlug(*m.confirmPR), shortPath(clonePath(*m.confirmPR)), strings.Join(opts, " "))
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// column is one column of the PR list.
+type column struct {
+	name   string // as written in a columns list
+	header string
+	width  int // fixed width, or 0 to share the flexible space
+	weight int // share of the flexible space when width is 0
+	min    int // narrowest a flexible column gets before it's dropped
+	// priority decides which columns go when the terminal is too narrow for
+	// all of them: the highest number is dropped first. A columns list can
+	// override it with "name:N".
+	priority int
+	// cell renders pr exactly w cells wide. hl holds match positions for
+	// the fuzzy fields (see fuzzyFields).
+	cell func(pr PR, w int, selected bool, hl [4][]int) string
+}
+
+// defaultColumns is what a view shows when neither it nor the config lists
+// columns.
+var defaultColumns = []string{"status", "ci", "repo", "number", "title", "author", "reviewer", "branch", "diff"}
+
+// columns lists every available column.
+var columns = []column{
+	{name: "status", header: "STATUS", width: 12, priority: 1, cell: statusCell},
+	{name: "ci", header: "CI", width: 3, priority: 4, cell: func(pr PR, w int, selected bool, _ [4][]int) string {
+		return ciStyle(pr, selected).Render(pad(ciSymbol(pr), w))
+	}},
+	{name: "repo", header: "REPO", weight: 30, min: 12, priority: 2, cell: func(pr PR, w int, selected bool, hl [4][]int) string {
+		return highlightCell(pr.Repository.Name, w, hl[0], pick(selected, selectedNormalStyle, normalStyle), matchStyle)
+	}},
+	{name: "owner/repo", header: "REPO", weight: 40, min: 20, priority: 2, cell: func(pr PR, w int, selected bool, hl [4][]int) string {
+		// Matches are positions in the repo name, which ends the slug; shift
+		// them past "owner/" (and "host/" off github.com).
+		slug := repoSlug(pr)
+		offset := len([]rune(slug)) - len([]rune(pr.Repository.Name))
+		positions := make([]int, len(hl[0]))
+		for i, p := range hl[0] {
+			positions[i] = p + offset
+		}
+		return highlightCell(slug, w, positions, pick(selected, selectedNormalStyle, normalStyle), matchStyle)
+	}},
+	{name: "number", header: "#", width: 6, priority: 2, cell: func(pr PR, w int, selected bool, _ [4][]int) string {
+		return pick(selected, selectedNormalStyle, normalStyle).Render(pad(fmt.Sprintf("#%d", pr.Number), w))
+	}},
+	{name: "title", header: "TITLE", weight: 40, min: 30, priority: 1, cell: func(pr PR, w int, selected bool, hl [4][]int) string {
+		return highlightCell(pr.Title, w, hl[1], pick(selected, selectedNormalStyle, normalStyle), matchStyle)
+	}},
+	{name: "author", header: "AUTHOR", width: 14, priority: 3, cell: func(pr PR, w int, selected bool, hl [4][]int) string {
+		return highlightCell(pr.Author.Login, w, hl[3], pick(selected, selectedStyle, authorStyle), matchStyle)
+	}},
+	{name: "reviewer", header: "REVIEWER", width: 14, priority: 6, cell: func(pr PR, w int, selected bool, _ [4][]int) string {
+		return pick(selected, selectedReviewRequestedStyle, reviewRequestedStyle).Render(pad(truncate(getReviewer(pr), w-1), w))
+	}},
+	{name: "branch", header: "BRANCH", weight: 30, min: 16, priority: 5, cell: func(pr PR, w int, selected bool, hl [4][]int) string {
+		return highlightCell(pr.HeadRefName, w, hl[2], pick(selected, selectedBranchStyle, branchStyle), matchStyle)
+	}},
+	{name: "base", header: "BASE", width: 14, priority: 7, cell: func(pr PR, w int, selected bool, _ [4][]int) string {
+		return pick(selected, selectedBranchStyle, branchStyle).Render(pad(truncate(pr.BaseRefName, w-1), w))
+	}},
+	{name: "diff", header: "+/-", width: 16, priority: 4, cell: diffCell},
+	{name: "age", header: "AGE", width: 5, priority: 5, cell: func(pr PR, w int, selected bool, _ [4][]int) string {
+		return pick(selected, selectedNormalStyle, dimStyle).Render(pad(shortAge(pr.CreatedAt), w))
+	}},
+	{name: "updated", header: "UPD", width: 5, priority: 5, cell: func(pr PR, w int, selected bool, _ [4][]int) string {
+		return pick(selected, selectedNormalStyle, dimStyle).Render(pad(shortAge(pr.UpdatedAt), w))
+	}},
+	{name: "comments", header: "CMTS", width: 5, priority: 7, cell: func(pr PR, w int, selected bool, _ [4][]int) string {
+		n := ""
+		if pr.Comments.TotalCount > 0 {
+			n = fmt.Sprint(pr.Comments.TotalCount)
+		}
+		return pick(selected, selectedCommentedStyle, commentedStyle).Render(pad(n, w))
+	}},
+	{name: "labels", header: "LABELS", weight: 20, min: 12, priority: 6, cell: func(pr PR, w int, selected bool, _ [4][]int) string {
+		names := make([]string, len(pr.Labels.Nodes))
+		for i, l := range pr.Labels.Nodes {
+			names[i] = l.Name
+		}
+		return pick(selected, selectedNormalStyle, dimStyle).Render(pad(truncate(strings.Join(names, ","), w-1), w))
+	}},
+}
+
+// shortAge is how long ago t was in one unit: "5m", "3h", "4d", "6w", "2y".
+func shortAge(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	d := time.Since(t)
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	case d < 14*24*time.Hour:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	case d < 365*24*time.Hour:
+		return fmt.Sprintf("%dw", int(d.Hours()/24/7))
+	}
+	return fmt.Sprintf("%dy", int(d.Hours()/24/365))
+}
+
+func pick(selected bool, sel, normal lipgloss.Style) lipgloss.Style {
+	if selected {
+		return sel
+	}
+	return normal
+}
+
+func statusCell(pr PR, w int, selected bool, _ [4][]int) string {
+	badge := getStatusBadge(pr)
+	if selected {
+		badge = getSelectedStatusBadge(pr)
+	}
+	return badge + strings.Repeat(" ", max(0, w-displayWidth(stripAnsi(badge))))
+}
+
+func diffCell(pr PR, w int, selected bool, _ [4][]int) string {
+	left := (w - 1) / 2
+	right := w - 1 - left
+	adds := padLeft(fmt.Sprintf("+%d", pr.Additions), left)
+	dels := padLeft(fmt.Sprintf("-%d", pr.Deletions), right)
+	return pick(selected, selectedAdditionsStyle, additionsStyle).Render(adds) + " " +
+		pick(selected, selectedDeletionsStyle, deletionsStyle).Render(dels)
+}
+
+func findColumn(name string) *column {
+	for i := range columns {
+		if columns[i].name == name {
+			return &columns[i]
+		}
+	}
+	return nil
+}
+
+func columnNames() []string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.name
+	}
+	return names
+}
+
+// parseColumn parses a columns list entry, "name" or "name:priority".
+func parseColumn(spec string) (column, error) {
+	name, prio, hasPrio := strings.Cut(strings.TrimSpace(spec), ":")
+	c := findColumn(strings.ToLower(name))
+	if c == nil {
+		return column{}, fmt.Errorf("unknown column %q (want one of %s)", name, strings.Join(columnNames(), ", "))
+	}
+	col := *c
+	if hasPrio {
+		n, err := strconv.Atoi(prio)
+		if err != nil || n < 1 || n > 9 {
+			return column{}, fmt.Errorf("column %q: priority must be 1-9, got %q", name, prio)
+		}
+		col.priority = n
+	}
+	return col, nil
+}
+
+// viewColumns returns the columns view i shows, in order: its own list,
+// else the config's top-level one, else defaultColumns.
+func viewColumns(i int) []column {
+	specs := defaultColumns
+	if len(cfg.Columns) > 0 {
+		specs = cfg.Columns
+	}
+	if len(views) > 0 && len(views[i].Columns) > 0 {
+		specs = views[i].Columns
+	}
+	var cols []column
+	for _, spec := range specs {
+		if c, err := parseColumn(spec); err == nil {
+			cols = append(cols, c)
+		}
+	}
+	return cols
+}
+
+// layoutColumns fits cols into a terminal total cells wide. Fixed columns
+// keep their width and flexible ones split what's left by weight. When even
+// the flexible columns' minimums don't fit, the lowest-priority column (the
+// rightmost, on a tie) is dropped until they do. It returns the columns
+// that made it, with their widths.
+func layoutColumns(cols []column, total int) ([]column, []int) {
+	const rowPadding = 4 // cursor + spacing
+	need := func(cols []column) int {
+		n := rowPadding
+		for _, c := range cols {
+			n += max(c.width, c.min)
+		}
+		return n
+	}
+	cols = append([]column(nil), cols...)
+	for len(cols) > 1 && need(cols) > total {
+		drop := 0
+		for i, c := range cols {
+			if c.priority >= cols[drop].priority {
+				drop = i
+			}
+		}
+		cols = append(cols[:drop], cols[drop+1:]...)
+	}
+
+	// Each flexible column gets its minimum, then a weighted share of the
+	// rest; the last one takes the rounding.
+	widths := make([]int, len(cols))
+	used, weights, lastFlex := rowPadding, 0, -1
+	for i, c := range cols {
+		widths[i] = max(c.width, c.min)
+		used += widths[i]
+		if c.width == 0 {
+			weights += c.weight
+			lastFlex = i
+		}
+	}
+	if lastFlex < 0 {
+		return cols, widths
+	}
+	extra := max(total-used, 0)
+	left := extra
+	for i, c := range cols {
+		if c.width > 0 {
+			continue
+		}
+		share := extra * c.weight / weights
+		if i == lastFlex {
+			share = left
+		}
+		widths[i] += share
+		left -= share
+	}
+	return cols, widths
+}
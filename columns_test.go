@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestLayoutColumns(t *testing.T) {
+	tests := []struct {
+		specs []string
+		total int
+		want  string // name:width, in order
+	}{
+		{[]string{"status", "title"}, 100, "status:12 title:84"},
+		{[]string{"repo", "title"}, 100, "repo:35 title:61"}, // flexible space split 30:40
+		{defaultColumns, 127, "status:12 ci:3 repo:12 number:6 title:30 author:14 reviewer:14 branch:16 diff:16"},
+		{defaultColumns, 126, "status:12 ci:3 repo:15 number:6 title:35 author:14 branch:21 diff:16"}, // reviewer goes first
+		{defaultColumns, 100, "status:12 ci:3 repo:13 number:6 title:32 author:14 diff:16"},
+		// Priority ties drop the rightmost first: diff before ci, number before repo.
+		{defaultColumns, 60, "status:12 repo:12 title:32"},
+		{[]string{"status", "title:9", "author"}, 50, "status:12 author:14"},
+		{[]string{"title"}, 10, "title:30"}, // the last column stays, however narrow
+	}
+	for _, tt := range tests {
+		var cols []column
+		for _, spec := range tt.specs {
+			c, err := parseColumn(spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cols = append(cols, c)
+		}
+		got, widths := layoutColumns(cols, tt.total)
+		var parts []string
+		for i, c := range got {
+			parts = append(parts, fmt.Sprintf("%s:%d", c.name, widths[i]))
+		}
+		if s := strings.Join(parts, " "); s != tt.want {
+			t.Errorf("layoutColumns(%v, %d) = %s, want %s", tt.specs, tt.total, s, tt.want)
+		}
+	}
+}
+
+func TestParseColumn(t *testing.T) {
+	tests := []struct{ spec, want string }{
+		{"title", "title priority 1"},
+		{" Author:7 ", "author priority 7"},
+		{"nope", `unknown column "nope"`},
+		{"title:0", "priority must be 1-9"},
+		{"title:x", "priority must be 1-9"},
+	}
+	for _, tt := range tests {
+		c, err := parseColumn(tt.spec)
+		got := fmt.Sprintf("%s priority %d", c.name, c.priority)
+		if err != nil {
+			got = err.Error()
+		}
+		if !strings.Contains(got, tt.want) {
+			t.Errorf("parseColumn(%q) = %s, want %s", tt.spec, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,418 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Config is the optional config file, $XDG_CONFIG_HOME/sup/config.toml
+// (~/.config/sup/config.toml by default, or $SUP_CONFIG). Environment
+// variables override its values and flags override both.
+type Config struct {
+	Orgs  []string          `toml:"orgs"`  // orgs to show, skipping detection (SUP_ORG)
+	Host  string            `toml:"host"`  // default GitHub host (SUP_HOST)
+	Hosts map[string]string `toml:"hosts"` // org → host (SUP_HOSTS)
+	// RepoRoots are the directories searched for clones, replacing
+	// defaultDevDirs. Relative paths are under $HOME.
+	RepoRoots   []string           `toml:"repo_roots"`
+	RepoDepth   int                `toml:"repo_depth"`   // levels below each root searched for clones; 0 is the default
+	CloneRoot   string             `toml:"clone_root"`   // where missing repos are cloned; first existing repo root if unset
+	Repos       map[string]string  `toml:"repos"`        // owner/repo → clone path, checked first
+	DefaultView string             `toml:"default_view"` // view the TUI opens on (SUP_VIEW)
+	Columns     []string           `toml:"columns"`      // "name" or "name:priority", for views that don't list their own
+	Editor      string             `toml:"editor"`       // for review bodies (SUP_EDITOR); $EDITOR if unset
+	DiffViewer  string             `toml:"diff_viewer"`  // command reading a patch on stdin (SUP_DIFF_VIEWER); hunk if unset
+	Theme       string             `toml:"theme"`        // color theme (SUP_THEME), see theme.go
+	Checkout    string             `toml:"checkout"`     // "branch" or "worktree" (SUP_CHECKOUT)
+	WorktreeDir string             `toml:"worktree_dir"` // worktree layout, see worktree.go
+	Keys        map[string]keyList `toml:"keys"`         // action → keys, see keys.go
+	Views       []viewConfig       `toml:"views"`
+
+	bindings keyBindings // Keys applied to the defaults
+}
+
+// viewConfig is one [[views]] table: a named PR list with its own search,
+// filter, sort and columns.
+type viewConfig struct {
+	Name string `toml:"name"`
+	// Search holds raw GitHub search queries, one shard each, replacing the
+	// org (or --mine) queries. "is:pr" is added when missing.
+	Search  []string `toml:"search"`
+	Host    string   `toml:"host"`    // host the Search queries run on; default host if empty
+	Filter  string   `toml:"filter"`  // always applied under the / filter, same syntax
+	Sort    string   `toml:"sort"`    // e.g. "-created,number"
+	Columns []string `toml:"columns"` // in display order, as for Config.Columns
+
+	filter filterExpr // Filter, parsed
+	sort   sortSpec   // Sort, parsed
+}
+
+var cfg Config
+
+// configLoaded reports whether a config file was read, for `sup config`.
+var configLoaded bool
+
+func configPath() string {
+	if p := os.Getenv("SUP_CONFIG"); p != "" {
+		return p
+	}
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		dir = filepath.Join(os.Getenv("HOME"), ".config")
+	}
+	return filepath.Join(dir, "sup", "config.toml")
+}
+
+// configError lists every problem found in the config file, so one run of
+// `sup config check` shows them all.
+type configError struct {
+	path string
+	errs []error
+}
+
+func (e *configError) Error() string {
+	if len(e.errs) == 1 {
+		return fmt.Sprintf("%s: %v", e.path, e.errs[0])
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %d problems:", e.path, len(e.errs))
+	for _, err := range e.errs {
+		b.WriteString("\n  " + err.Error())
+	}
+	return b.String()
+}
+
+// loadConfig reads and validates the config file. A missing file is fine.
+// On error cfg is left empty.
+func loadConfig() error {
+	path := configPath()
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var c Config
+	md, err := toml.Decode(string(data), &c)
+	if err != nil {
+		var perr toml.ParseError
+		if errors.As(err, &perr) {
+			return &configError{path, []error{errors.New(perr.ErrorWithPosition())}}
+		}
+		return &configError{path, []error{err}}
+	}
+	var errs []error
+	for _, key := range md.Undecoded() {
+		errs = append(errs, unknownKeyError(key))
+	}
+	errs = append(errs, c.validate()...)
+	if len(errs) > 0 {
+		return &configError{path, errs}
+	}
+	cfg, configLoaded = c, true
+	bindings = c.bindings
+	return nil
+}
+
+// configKeys returns the keys a TOML table decoded into t accepts.
+func configKeys(t reflect.Type) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		if tag := t.Field(i).Tag.Get("toml"); tag != "" {
+			keys = append(keys, tag)
+		}
+	}
+	return keys
+}
+
+// unknownKeyError names an unrecognised key, suggesting the closest valid
+// one when it looks like a typo.
+func unknownKeyError(key toml.Key) error {
+	known := configKeys(reflect.TypeOf(Config{}))
+	if len(key) > 1 && key[0] == "views" {
+		known = configKeys(reflect.TypeOf(viewConfig{}))
+	}
+	if best := closest(key[len(key)-1], known); best != "" {
+		return fmt.Errorf("unknown key %q (did you mean %q?)", key.String(), best)
+	}
+	return fmt.Errorf("unknown key %q", key.String())
+}
+
+// closest returns the candidate within two edits of s, or "".
+func closest(s string, candidates []string) string {
+	best, bestDist := "", 3
+	for _, c := range candidates {
+		if d := editDistance(s, c); d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func validHost(h string) bool {
+	h = normalizeHost(h)
+	return h != "" && !strings.ContainsAny(h, "/ \t")
+}
+
+// validate checks everything that can be checked without touching the
+// disk or the network, and fills in parsed fields.
+func (c *Config) validate() []error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	for _, o := range c.Orgs {
+		if o = strings.TrimSpace(o); o == "" || strings.ContainsAny(o, "/ ,") {
+			fail("orgs: invalid org name %q", o)
+		}
+	}
+	if c.Host != "" && !validHost(c.Host) {
+		fail("host: invalid host %q", c.Host)
+	}
+	for org, host := range c.Hosts {
+		if strings.TrimSpace(org) == "" || strings.ContainsAny(org, "/ ,=") {
+			fail("hosts: invalid org name %q", org)
+		}
+		if !validHost(host) {
+			fail("hosts.%s: invalid host %q", org, host)
+		}
+	}
+	for _, dir := range c.RepoRoots {
+		if strings.TrimSpace(dir) == "" {
+			fail("repo_roots: empty path")
+		}
+	}
+	if c.CloneRoot != "" && strings.TrimSpace(c.CloneRoot) == "" {
+		fail("clone_root: empty path")
+	}
+	if c.RepoDepth < 0 || c.RepoDepth > 8 {
+		fail("repo_depth: %d is out of range (1-8, or 0 for the default of %d)", c.RepoDepth, defaultRepoDepth)
+	}
+	for repo, path := range c.Repos {
+		if owner, name, ok := strings.Cut(repo, "/"); !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+			fail("repos: key %q should be owner/repo", repo)
+		}
+		if strings.TrimSpace(path) == "" {
+			fail("repos.%q: empty path", repo)
+		}
+	}
+	if _, ok := themes[strings.ToLower(c.Theme)]; c.Theme != "" && !ok {
+		fail("theme: unknown theme %q (want one of %s)", c.Theme, strings.Join(themeNames(), ", "))
+	}
+	if c.Checkout != "" && !validCheckoutMode(c.Checkout) {
+		fail("checkout: unknown mode %q (want %s)", c.Checkout, strings.Join(checkoutModes, " or "))
+	}
+	if c.WorktreeDir != "" {
+		if err := validateWorktreeDir(c.WorktreeDir); err != nil {
+			fail("worktree_dir: %v", err)
+		}
+	}
+	var keyErrs []error
+	c.bindings, keyErrs = bindKeys(c.Keys)
+	errs = append(errs, keyErrs...)
+	for _, spec := range c.Columns {
+		if _, err := parseColumn(spec); err != nil {
+			fail("columns: %v", err)
+		}
+	}
+
+	seen := map[string]bool{}
+	slugs := map[string]string{} // view caches are named by slug
+	for i := range c.Views {
+		v := &c.Views[i]
+		where := fmt.Sprintf("views[%d]", i)
+		if v.Name != "" {
+			where = fmt.Sprintf("view %q", v.Name)
+		}
+		switch key := strings.ToLower(v.Name); {
+		case v.Name == "":
+			fail("%s: name is required", where)
+		case seen[key]:
+			fail("%s: duplicate view name", where)
+		case viewSlug(v.Name) == "":
+			fail("%s: name needs a letter or digit", where)
+		case slugs[viewSlug(v.Name)] != "":
+			fail("%s: clashes with view %q (both would cache to view-%s.json)", where, slugs[viewSlug(v.Name)], viewSlug(v.Name))
+		default:
+			seen[key] = true
+			slugs[viewSlug(v.Name)] = v.Name
+		}
+		for _, q := range v.Search {
+			if strings.TrimSpace(q) == "" {
+				fail("%s: search: empty query", where)
+			}
+		}
+		if v.Host != "" && !validHost(v.Host) {
+			fail("%s: host: invalid host %q", where, v.Host)
+		}
+		expr, err := parseFilter(v.Filter)
+		if err != nil {
+			fail("%s: filter: %v", where, err)
+		}
+		v.filter = expr
+		spec, err := parseSort(v.Sort)
+		if err != nil {
+			fail("%s: sort: %v", where, err)
+		}
+		v.sort = spec
+		for _, spec := range v.Columns {
+			if _, err := parseColumn(spec); err != nil {
+				fail("%s: columns: %v", where, err)
+			}
+		}
+	}
+
+	if dv := strings.ToLower(c.DefaultView); dv != "" && dv != "all" && dv != "mine" && !seen[dv] {
+		fail("default_view: no view named %q", c.DefaultView)
+	}
+	return errs
+}
+
+// warnings reports settings that are valid but probably not what was
+// meant: paths that don't exist and commands that aren't installed.
+func (c *Config) warnings() []string {
+	var warns []string
+	for _, dir := range c.RepoRoots {
+		if _, err := os.Stat(expandPath(dir)); err != nil {
+			warns = append(warns, fmt.Sprintf("repo_roots: %s does not exist", dir))
+		}
+	}
+	if c.CloneRoot != "" {
+		if _, err := os.Stat(expandPath(c.CloneRoot)); err != nil {
+			warns = append(warns, fmt.Sprintf("clone_root: %s does not exist (it will be created)", c.CloneRoot))
+		}
+	}
+	repos := make([]string, 0, len(c.Repos))
+	for repo := range c.Repos {
+		repos = append(repos, repo)
+	}
+	sort.Strings(repos)
+	for _, repo := range repos {
+		path := c.Repos[repo]
+		if _, err := os.Stat(filepath.Join(expandPath(path), ".git")); err != nil {
+			warns = append(warns, fmt.Sprintf("repos.%q: %s is not a git clone", repo, path))
+		}
+	}
+	for key, command := range map[string]string{"editor": c.Editor, "diff_viewer": c.DiffViewer} {
+		if f := strings.Fields(command); len(f) > 0 {
+			if _, err := exec.LookPath(f[0]); err != nil {
+				warns = append(warns, fmt.Sprintf("%s: %s not found in PATH", key, f[0]))
+			}
+		}
+	}
+	sort.Strings(warns)
+	return warns
+}
+
+// expandPath expands a leading ~ and makes relative paths relative to
+// $HOME, the way defaultDevDirs are.
+func expandPath(p string) string {
+	home := os.Getenv("HOME")
+	if p == "~" {
+		return home
+	}
+	if rest, ok := strings.CutPrefix(p, "~/"); ok {
+		return filepath.Join(home, rest)
+	}
+	if !filepath.IsAbs(p) {
+		return filepath.Join(home, p)
+	}
+	return p
+}
+
+// configTemplate is what `sup config init` writes.
+const configTemplate = `# sup configuration. Environment variables (SUP_ORG, SUP_HOST, ...)
+# override these values, and flags override both. Check it with
+# 'sup config check'.
+
+# Orgs to show, skipping detection.
+# orgs = ["acme", "acme-infra"]
+
+# Default GitHub host, and hosts for individual orgs.
+# host = "github.com"
+# [hosts]
+# acme-infra = "ghe.acme.com"
+
+# Where to look for clones, instead of ~/Development, ~/dev, ~/src, ...
+# Relative paths are under your home directory. Clones are found up to
+# repo_depth levels down (3 by default, enough for src/github.com/owner/repo)
+# and matched to GitHub repos by their remotes; 'sup repos' lists them.
+# repo_roots = ["~/work", "src"]
+# repo_depth = 3
+
+# Where checkout clones repos you don't have yet (it asks first). Defaults
+# to the first repo root that exists.
+# clone_root = "~/work"
+
+# Clones that live somewhere else, checked before repo_roots.
+# [repos]
+# "acme/backend-api" = "~/work/api"
+
+# View the TUI opens on.
+# default_view = "Review queue"
+
+# Columns for views that don't set their own, in order. Add :N to change a
+# column's priority (1-9); the highest numbers are hidden first when the
+# terminal is too narrow.
+# columns = ["status", "ci", "repo", "number", "title", "author:2", "diff", "age"]
+
+# Editor for review bodies ($EDITOR if unset) and the command diffs are
+# piped to (hunk if unset).
+# editor = "nvim"
+# diff_viewer = "delta --side-by-side"
+
+# Color theme: default (follows the terminal background), dark, light,
+# high-contrast, colorblind-safe or no-color. NO_COLOR selects no-color
+# unless a theme is set here.
+# theme = "default"
+
+# How Enter and 'sup checkout' check PRs out: "branch" switches the clone
+# to the PR's branch, "worktree" adds a worktree for it (w always does).
+# worktree_dir is relative to the clone's parent directory and may use
+# {owner}, {repo}, {number} and {branch}.
+# checkout = "worktree"
+# worktree_dir = "{repo}-worktrees/pr-{number}"
+
+# Rebind keys by action name (see the ? overlay); "" unbinds.
+# [keys]
+# refresh = "ctrl+r"
+# open = ["o", "b"]
+
+# Named views, shown as tabs.
+# [[views]]
+# name = "Review queue"
+# search = ["review-requested:@me is:open"]
+# filter = "-is:draft"
+# sort = "-created"
+`
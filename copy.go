@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// copyFormats are the copy prompt's choices. Each renders one PR; the list
+// choice renders every PR in the filtered list.
+var copyFormats = []struct {
+	key, label string
+	format     func(pr PR) string
+}{
+	{"u", "url", prURL},
+	{"r", "ref", prRef},
+	{"m", "markdown", prMarkdownLink},
+	{"b", "branch", func(pr PR) string { return pr.HeadRefName }},
+	{"g", "gh checkout", func(pr PR) string {
+		return fmt.Sprintf("gh pr checkout %d --repo %s", pr.Number, repoSlug(pr))
+	}},
+	{"s", "summary", prSummary},
+}
+
+// prRef is the short reference GitHub links in comments: owner/repo#123.
+func prRef(pr PR) string {
+	return fmt.Sprintf("%s#%d", repoSlug(pr), pr.Number)
+}
+
+func prMarkdownLink(pr PR) string {
+	return fmt.Sprintf("[%s](%s)", markdownEscaper.Replace(pr.Title), prURL(pr))
+}
+
+// markdownEscaper keeps brackets in titles from ending the link text early.
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`)
+
+// prSummary is a few lines for pasting into chat:
+//
+//	Add user authentication flow
+//	acme-corp/backend-api#142 · https://github.com/acme-corp/backend-api/pull/142
+//	@sarah-dev · Approved · CI passing · +847 -123
+func prSummary(pr PR) string {
+	meta := []string{"@" + pr.Author.Login, strings.Trim(statusBadgeText(pr), "[]")}
+	if ci := ciState(pr); ci != "" {
+		meta = append(meta, "CI "+ci)
+	}
+	meta = append(meta, fmt.Sprintf("+%d -%d", pr.Additions, pr.Deletions))
+	return pr.Title + "\n" + prRef(pr) + " · " + prURL(pr) + "\n" + strings.Join(meta, " · ")
+}
+
+// statusBadgeText is the unstyled text of getStatusBadge.
+func statusBadgeText(pr PR) string {
+	switch statusLabelForFilter(pr) {
+	case "draft":
+		return "[Draft]"
+	case "approved":
+		return "[Approved]"
+	case "denied":
+		return "[Denied]"
+	case "commented":
+		return "[Commented]"
+	case "review":
+		return "[Review]"
+	}
+	return "[Open]"
+}
+
+// prChecklist renders PRs as a markdown task list, one line each.
+func prChecklist(prs []PR) string {
+	var s strings.Builder
+	for _, pr := range prs {
+		fmt.Fprintf(&s, "- [ ] %s (%s, @%s)\n", prMarkdownLink(pr), prRef(pr), pr.Author.Login)
+	}
+	return s.String()
+}
+
+// handleCopyKey handles input while the copy prompt is showing. Pressing
+// the copy key again copies the URL, so the old single-key copy still
+// works as a double tap.
+func (m model) handleCopyKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+	pr := *m.confirmPR
+	m.confirmAction = ""
+	m.confirmPR = nil
+
+	var text, what string
+	switch {
+	case actionFor(key) == "copy":
+		text, what = prURL(pr), "link"
+	case key == "l":
+		if len(m.filtered) == 0 {
+			return m, nil
+		}
+		text, what = prChecklist(m.filtered), fmt.Sprintf("list of %d PRs", len(m.filtered))
+	default:
+		for _, f := range copyFormats {
+			if key == f.key {
+				text, what = f.format(pr), f.label
+			}
+		}
+	}
+	// Anything else cancels.
+	if text == "" {
+		return m, nil
+	}
+	if via, err := copyToClipboard(text); err != nil {
+		m.actionStatus = "Error copying: " + err.Error()
+	} else if key == "l" {
+		m.actionStatus = fmt.Sprintf("✓ Copied %s (%s)", what, via)
+	} else {
+		m.actionStatus = fmt.Sprintf("✓ Copied PR #%d %s (%s)", pr.Number, what, via)
+	}
+	return m, nil
+}
+
+func (m model) copyPromptView() string {
+	opts := make([]string, 0, len(copyFormats)+1)
+	for _, f := range copyFormats {
+		if f.format(*m.confirmPR) != "" {
+			opts = append(opts, "["+f.key+"]"+f.label[1:])
+		}
+	}
+	opts = append(opts, fmt.Sprintf("[l]ist (%d)", len(m.filtered)))
+	return fmt.Sprintf("  Copy PR #%d: %s · esc cancel", m.confirmPR.Number, strings.Join(opts, " "))
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// PRDetail is everything the detail pane shows beyond what fits on a row.
+// It's fetched lazily for the PR under the cursor and cached per PR.
+type PRDetail struct {
+	Body        string    `json:"body"`
+	BaseRefName string    `json:"baseRefName"`
+	HeadRefName string    `json:"headRefName"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+	// Mergeable is MERGEABLE, CONFLICTING or UNKNOWN.
+	Mergeable        string `json:"mergeable"`
+	MergeStateStatus string `json:"mergeStateStatus"`
+	Milestone        *struct {
+		Title string `json:"title"`
+	} `json:"milestone"`
+	Labels struct {
+		Nodes []struct {
+			Name string `json:"name"`
+		} `json:"nodes"`
+	} `json:"labels"`
+	Assignees struct {
+		Nodes []struct {
+			Login string `json:"login"`
+		} `json:"nodes"`
+	} `json:"assignees"`
+	ReviewRequests struct {
+		Nodes []struct {
+			RequestedReviewer struct {
+				Login string `json:"login"`
+				Name  string `json:"name"`
+			} `json:"requestedReviewer"`
+		} `json:"nodes"`
+	} `json:"reviewRequests"`
+	Reviews struct {
+		Nodes []struct {
+			Author struct {
+				Login string `json:"login"`
+			} `json:"author"`
+			State       string    `json:"state"`
+			SubmittedAt time.Time `json:"submittedAt"`
+		} `json:"nodes"`
+	} `json:"reviews"`
+}
+
+type detailLoadedMsg struct {
+	key    string
+	detail PRDetail
+	err    error
+}
+
+func fetchDetailCmd(pr PR) tea.Cmd {
+	return func() tea.Msg {
+		d, err := backendFor(prHost(pr)).FetchPRDetail(pr)
+		return detailLoadedMsg{key: prKey(pr), detail: d, err: err}
+	}
+}
+
+// ensureDetail starts loading the detail for the PR under the cursor when
+// the pane is open and we haven't fetched (or started fetching) it yet.
+func (m *model) ensureDetail() tea.Cmd {
+	if !m.detailOpen || len(m.filtered) == 0 || m.cursor >= len(m.filtered) {
+		return nil
+	}
+	pr := m.filtered[m.cursor]
+	key := prKey(pr)
+	if key != m.detailKey {
+		m.detailKey = key
+		m.detailScroll = 0
+	}
+	if _, ok := m.details[key]; ok || m.detailLoading[key] {
+		return nil
+	}
+	m.detailLoading[key] = true
+	return fetchDetailCmd(pr)
+}
+
+// detailHeight is how many lines the pane gets: about half the screen.
+func (m model) detailHeight() int {
+	if !m.detailOpen {
+		return 0
+	}
+	h := m.height / 2
+	if h < 8 {
+		h = 8
+	}
+	return h
+}
+
+func (m model) detailView(width int) string {
+	height := m.detailHeight()
+	if len(m.filtered) == 0 || m.cursor >= len(m.filtered) {
+		return ""
+	}
+	pr := m.filtered[m.cursor]
+	key := prKey(pr)
+
+	var lines []string
+	lines = append(lines, dimStyle.Render(strings.Repeat("─", width)))
+	lines = append(lines, titleStyle.Render(truncate(fmt.Sprintf("%s  %s", key, pr.Title), width)))
+
+	d, ok := m.details[key]
+	switch {
+	case m.detailErrs[key] != nil:
+		lines = append(lines, changesRequestedStyle.Render(truncate("Error: "+m.detailErrs[key].Error(), width)))
+	case !ok:
+		lines = append(lines, dimStyle.Render(spinnerFrames[m.spinnerFrame]+" Loading details..."))
+	default:
+		field := func(name, value string) {
+			if value != "" {
+				lines = append(lines, labelStyle.Render(pad(name, 11))+truncate(value, width-11))
+			}
+		}
+		field("Branches", d.BaseRefName+" ← "+d.HeadRefName)
+		field("Created", relativeTime(d.CreatedAt))
+		field("Updated", relativeTime(d.UpdatedAt))
+		field("Mergeable", mergeableLabel(d))
+		var labels, assignees, requested []string
+		for _, l := range d.Labels.Nodes {
+			labels = append(labels, l.Name)
+		}
+		for _, a := range d.Assignees.Nodes {
+			assignees = append(assignees, a.Login)
+		}
+		for _, rr := range d.ReviewRequests.Nodes {
+			name := rr.RequestedReviewer.Login
+			if name == "" {
+				name = rr.RequestedReviewer.Name
+			}
+			if name != "" {
+				requested = append(requested, name)
+			}
+		}
+		field("Labels", strings.Join(labels, ", "))
+		field("Assignees", strings.Join(assignees, ", "))
+		if d.Milestone != nil {
+			field("Milestone", d.Milestone.Title)
+		}
+		field("Requested", strings.Join(requested, ", "))
+		if len(d.Reviews.Nodes) > 0 {
+			lines = append(lines, labelStyle.Render("Reviews"))
+			for _, r := range d.Reviews.Nodes {
+				lines = append(lines, "  "+reviewStateStyle(r.State).Render(pad(reviewStateLabel(r.State), 18))+
+					pad(truncate(r.Author.Login, 16), 17)+dimStyle.Render(relativeTime(r.SubmittedAt)))
+			}
+		}
+		lines = append(lines, "")
+		body := strings.TrimSpace(d.Body)
+		if body == "" {
+			lines = append(lines, dimStyle.Render("No description provided."))
+		} else {
+			lines = append(lines, renderMarkdown(body, width)...)
+		}
+	}
+
+	// The title rows stay pinned; everything below them scrolls.
+	const pinned = 2
+	scroll := m.detailScroll
+	if maxScroll := len(lines) - height; scroll > maxScroll {
+		scroll = maxScroll
+	}
+	if scroll < 0 {
+		scroll = 0
+	}
+	visible := append([]string{}, lines[:pinned]...)
+	visible = append(visible, lines[pinned+scroll:]...)
+	if len(visible) > height {
+		visible = visible[:height]
+	}
+	out := make([]string, len(visible))
+	for i, l := range visible {
+		out[i] = "  " + l
+	}
+	return strings.Join(out, "\n") + "\n"
+}
+
+func mergeableLabel(d PRDetail) string {
+	switch d.Mergeable {
+	case "MERGEABLE":
+		if d.MergeStateStatus != "" && d.MergeStateStatus != "CLEAN" {
+			return "yes (" + strings.ToLower(d.MergeStateStatus) + ")"
+		}
+		return "yes"
+	case "CONFLICTING":
+		return "no — conflicts"
+	case "UNKNOWN":
+		return "checking..."
+	}
+	return ""
+}
+
+func reviewStateLabel(state string) string {
+	switch state {
+	case "APPROVED":
+		return "✓ approved"
+	case "CHANGES_REQUESTED":
+		return "✗ changes"
+	case "COMMENTED":
+		return "• commented"
+	case "DISMISSED":
+		return "- dismissed"
+	case "PENDING":
+		return "… pending"
+	}
+	return strings.ToLower(state)
+}
+
+func reviewStateStyle(state string) lipgloss.Style {
+	switch state {
+	case "APPROVED":
+		return approvedStyle
+	case "CHANGES_REQUESTED":
+		return changesRequestedStyle
+	case "COMMENTED":
+		return commentedStyle
+	}
+	return draftStyle
+}
+
+func relativeTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	d := time.Since(t)
+	var ago string
+	switch {
+	case d < time.Minute:
+		ago = "just now"
+	case d < time.Hour:
+		ago = fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		ago = fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		ago = fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+	return t.Local().Format("2006-01-02 15:04") + " (" + ago + ")"
+}
+
+var (
+	mdBold       = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	mdInlineCode = regexp.MustCompile("`([^`]+)`")
+	mdLink       = regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`)
+	mdImage      = regexp.MustCompile(`!\[([^\]]*)\]\([^)]+\)`)
+	mdHTMLTag    = regexp.MustCompile(`<[^>]+>`)
+)
+
+// renderMarkdown is a deliberately small markdown renderer for PR bodies:
+// headings, lists, quotes, fenced code and the common inline forms. It
+// wraps to width and returns styled lines.
+func renderMarkdown(src string, width int) []string {
+	boldStyle := lipgloss.NewStyle().Bold(true)
+
+	inline := func(s string) string {
+		s = mdImage.ReplaceAllString(s, "[image: $1]")
+		s = mdHTMLTag.ReplaceAllString(s, "")
+		s = mdLink.ReplaceAllString(s, "$1 <$2>")
+		s = mdBold.ReplaceAllStringFunc(s, func(m string) string {
+			return boldStyle.Render(strings.Trim(m, "*_"))
+		})
+		s = mdInlineCode.ReplaceAllStringFunc(s, func(m string) string {
+			return codeStyle.Render(strings.Trim(m, "`"))
+		})
+		return s
+	}
+	// wrap word-wraps s, starting the first line with first and every
+	// continuation line with rest.
+	wrap := func(s, first, rest string, style *lipgloss.Style) []string {
+		w := width - displayWidth(first)
+		if w < 10 {
+			w = 10
+		}
+		rendered := lipgloss.NewStyle().Width(w).Render(s)
+		var out []string
+		for i, l := range strings.Split(rendered, "\n") {
+			l = strings.TrimRight(l, " ")
+			if style != nil {
+				l = style.Render(l)
+			}
+			if i == 0 {
+				out = append(out, first+l)
+			} else {
+				out = append(out, rest+l)
+			}
+		}
+		return out
+	}
+
+	var lines []string
+	inFence := false
+	for _, raw := range strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(raw)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		switch {
+		case inFence:
+			lines = append(lines, "  "+codeStyle.Render(truncateToWidth(raw, width-2)))
+		case trimmed == "":
+			if len(lines) > 0 && lines[len(lines)-1] != "" {
+				lines = append(lines, "")
+			}
+		case strings.HasPrefix(trimmed, "#"):
+			lines = append(lines, wrap(strings.TrimSpace(strings.TrimLeft(trimmed, "#")), "", "", &sectionStyle)...)
+		case strings.HasPrefix(trimmed, "> "):
+			lines = append(lines, wrap(inline(strings.TrimPrefix(trimmed, "> ")), "│ ", "│ ", &quoteStyle)...)
+		case strings.HasPrefix(trimmed, "- [ ] "), strings.HasPrefix(trimmed, "* [ ] "):
+			lines = append(lines, wrap(inline(trimmed[6:]), "☐ ", "  ", nil)...)
+		case strings.HasPrefix(trimmed, "- [x] "), strings.HasPrefix(trimmed, "* [x] "):
+			lines = append(lines, wrap(inline(trimmed[6:]), "☑ ", "  ", nil)...)
+		case strings.HasPrefix(trimmed, "- "), strings.HasPrefix(trimmed, "* "), strings.HasPrefix(trimmed, "+ "):
+			indent := strings.Repeat(" ", (len(raw)-len(strings.TrimLeft(raw, " ")))/2*2)
+			lines = append(lines, wrap(inline(trimmed[2:]), indent+"• ", indent+"  ", nil)...)
+		case strings.HasPrefix(trimmed, "---") || strings.HasPrefix(trimmed, "***"):
+			lines = append(lines, strings.Repeat("─", min(width, 20)))
+		default:
+			lines = append(lines, wrap(inline(trimmed), "", "", nil)...)
+		}
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
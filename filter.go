@@ -0,0 +1,644 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Filter queries. The / line is parsed into a small boolean AST:
+//
+//	repo:backend-api -is:draft review:@me (label:bug OR label:security) size:>500 sort:-updated
+//
+// Terms next to each other are ANDed; OR, NOT (or a leading -) and
+// parentheses combine them. AND binds tighter than OR, as on GitHub.
+// field:value terms match one attribute; anything else is a free-text
+// term (see fuzzy.go). The legacy shorthands still work: @user matches
+// requested reviewers and !user matches authors.
+
+type filterExpr interface {
+	match(pr PR) bool
+}
+
+type (
+	andExpr  []filterExpr
+	orExpr   []filterExpr
+	notExpr  struct{ x filterExpr }
+	predExpr func(pr PR) bool
+)
+
+func (e andExpr) match(pr PR) bool {
+	for _, x := range e {
+		if !x.match(pr) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e orExpr) match(pr PR) bool {
+	for _, x := range e {
+		if x.match(pr) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e notExpr) match(pr PR) bool { return !e.x.match(pr) }
+
+func (e predExpr) match(pr PR) bool { return e(pr) }
+
+// sortExpr is a sort: term. It matches everything; filterSort pulls the
+// order out of the query.
+type sortExpr sortSpec
+
+func (sortExpr) match(PR) bool { return true }
+
+// filterSort returns the order given by a sort: term in expr, or nil.
+func filterSort(expr filterExpr) sortSpec {
+	switch e := expr.(type) {
+	case sortExpr:
+		return sortSpec(e)
+	case andExpr:
+		for _, x := range e {
+			if spec := filterSort(x); spec != nil {
+				return spec
+			}
+		}
+	}
+	return nil
+}
+
+// filterPRs returns the PRs expr matches. A nil expr (empty query) matches
+// everything.
+func filterPRs(prs []PR, expr filterExpr) []PR {
+	if expr == nil {
+		return prs
+	}
+	var out []PR
+	for _, pr := range prs {
+		if expr.match(pr) {
+			out = append(out, pr)
+		}
+	}
+	return out
+}
+
+type filterTokenKind int
+
+const (
+	tokWord filterTokenKind = iota
+	tokLParen
+	tokRParen
+	tokAnd
+	tokOr
+	tokNot
+	tokEOF
+)
+
+type filterToken struct {
+	kind   filterTokenKind
+	text   string
+	quoted bool // the word was (or started with) a quoted string: no field:value
+	pos    int
+}
+
+// lexFilter splits a query into tokens. Quotes group words ("dark mode",
+// label:"needs review"); a - directly before a term negates it.
+func lexFilter(q string) ([]filterToken, error) {
+	var toks []filterToken
+	rs := []rune(q)
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case r == '(':
+			toks = append(toks, filterToken{kind: tokLParen, pos: i})
+			i++
+			continue
+		case r == ')':
+			toks = append(toks, filterToken{kind: tokRParen, pos: i})
+			i++
+			continue
+		case r == '-' && i+1 < len(rs) && !unicode.IsSpace(rs[i+1]) && rs[i+1] != ')':
+			toks = append(toks, filterToken{kind: tokNot, pos: i})
+			i++
+			continue
+		}
+
+		start := i
+		var word strings.Builder
+		quoted := r == '"'
+		inQuote := false
+		for ; i < len(rs); i++ {
+			c := rs[i]
+			if c == '"' {
+				inQuote = !inQuote
+				continue
+			}
+			if !inQuote && (unicode.IsSpace(c) || c == '(' || c == ')') {
+				break
+			}
+			word.WriteRune(c)
+		}
+		if inQuote {
+			return nil, fmt.Errorf("unterminated quote at col %d", start+1)
+		}
+		tok := filterToken{kind: tokWord, text: word.String(), quoted: quoted, pos: start}
+		if !quoted {
+			switch tok.text {
+			case "AND":
+				tok.kind = tokAnd
+			case "OR", "|":
+				tok.kind = tokOr
+			case "NOT":
+				tok.kind = tokNot
+			}
+		}
+		toks = append(toks, tok)
+	}
+	return append(toks, filterToken{kind: tokEOF, pos: len(rs)}), nil
+}
+
+type filterParser struct {
+	toks []filterToken
+	pos  int
+}
+
+func (p *filterParser) peek() filterToken { return p.toks[p.pos] }
+
+func (p *filterParser) next() filterToken {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// parseFilter parses a query. An empty query yields a nil expression.
+func parseFilter(q string) (filterExpr, error) {
+	toks, err := lexFilter(q)
+	if err != nil {
+		return nil, err
+	}
+	if len(toks) == 1 {
+		return nil, nil
+	}
+	p := &filterParser{toks: toks}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %s at col %d", describeToken(t), t.pos+1)
+	}
+	return expr, nil
+}
+
+func (p *filterParser) parseOr() (filterExpr, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	terms := orExpr{first}
+	for p.peek().kind == tokOr {
+		t := p.next()
+		x, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		if hasSort(first) || hasSort(x) {
+			return nil, fmt.Errorf("sort: can't be part of an OR (col %d); put it beside the OR instead", t.pos+1)
+		}
+		terms = append(terms, x)
+	}
+	if len(terms) == 1 {
+		return first, nil
+	}
+	return terms, nil
+}
+
+// hasSort reports whether expr has a sort: term where filterSort finds it.
+// parseOr and parseUnary keep them anywhere else out.
+func hasSort(expr filterExpr) bool {
+	switch e := expr.(type) {
+	case sortExpr:
+		return true
+	case andExpr:
+		for _, x := range e {
+			if hasSort(x) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (p *filterParser) parseAnd() (filterExpr, error) {
+	var terms andExpr
+	for {
+		switch t := p.peek(); t.kind {
+		case tokOr, tokRParen, tokEOF:
+			if len(terms) == 0 {
+				return nil, fmt.Errorf("expected a term at col %d, got %s", t.pos+1, describeToken(t))
+			}
+			if len(terms) == 1 {
+				return terms[0], nil
+			}
+			return terms, nil
+		case tokAnd:
+			p.next()
+			if len(terms) == 0 {
+				return nil, fmt.Errorf("AND at col %d needs a term before it", t.pos+1)
+			}
+			if k := p.peek().kind; k == tokOr || k == tokRParen || k == tokEOF {
+				return nil, fmt.Errorf("AND at col %d needs a term after it", t.pos+1)
+			}
+		}
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, x)
+	}
+}
+
+func (p *filterParser) parseUnary() (filterExpr, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNot:
+		if k := p.peek().kind; k != tokWord && k != tokLParen && k != tokNot {
+			return nil, fmt.Errorf("NOT at col %d needs a term after it", t.pos+1)
+		}
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if hasSort(x) {
+			return nil, fmt.Errorf("sort: can't be negated (col %d); sort:-field reverses the order", t.pos+1)
+		}
+		return notExpr{x}, nil
+	case tokLParen:
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next().kind != tokRParen {
+			return nil, fmt.Errorf("missing ) for ( at col %d", t.pos+1)
+		}
+		return x, nil
+	case tokWord:
+		return termExpr(t)
+	}
+	return nil, fmt.Errorf("unexpected %s at col %d", describeToken(t), t.pos+1)
+}
+
+func describeToken(t filterToken) string {
+	switch t.kind {
+	case tokLParen:
+		return "("
+	case tokRParen:
+		return ")"
+	case tokAnd:
+		return "AND"
+	case tokOr:
+		return "OR"
+	case tokNot:
+		return "NOT"
+	case tokEOF:
+		return "end of filter"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// filterFields maps each field: qualifier to the function building its
+// predicate from the (lowercased) value.
+var filterFields = map[string]func(v string) (predExpr, error){
+	"repo":        repoPredicate,
+	"org":         orgPredicate,
+	"owner":       orgPredicate,
+	"author":      authorPredicate,
+	"is":          isPredicate,
+	"review":      reviewPredicate,
+	"reviewed-by": reviewedByPredicate,
+	"label":       labelPredicate,
+	"size":        sizePredicate,
+	"age":         agePredicate,
+	"ci":          ciPredicate,
+	"sort":        nil, // handled by termExpr: orders rather than matches
+}
+
+func termExpr(t filterToken) (filterExpr, error) {
+	word := strings.ToLower(t.text)
+	if !t.quoted {
+		field, value, ok := strings.Cut(word, ":")
+		if build, known := filterFields[field]; ok && known {
+			if value == "" {
+				return nil, fmt.Errorf("%s: needs a value (col %d)", field, t.pos+1)
+			}
+			if field == "sort" {
+				spec, err := parseSort(value)
+				if err != nil {
+					return nil, fmt.Errorf("sort:%s: %v", value, err)
+				}
+				return sortExpr(spec), nil
+			}
+			return build(value)
+		}
+		// fix: and feat: are words in titles, but lable: is a typo.
+		if ok {
+			if typo := fieldTypo(field); typo != "" {
+				return nil, fmt.Errorf("unknown field %q at col %d (did you mean %q?)", field+":", t.pos+1, typo+":")
+			}
+		}
+		if name, ok := strings.CutPrefix(word, "@"); ok && name != "" {
+			return predExpr(func(pr PR) bool {
+				return strings.Contains(strings.ToLower(getRequestedReviewerNames(pr)), name)
+			}), nil
+		}
+		if name, ok := strings.CutPrefix(word, "!"); ok && name != "" {
+			return predExpr(func(pr PR) bool {
+				return strings.Contains(strings.ToLower(pr.Author.Login), name)
+			}), nil
+		}
+	}
+	return textExpr{pattern: []rune(word), exact: t.quoted}, nil
+}
+
+// fieldTypo returns the field s looks like a misspelling of, or "". Typos
+// rarely get the first letter wrong, so only fields starting with the same
+// one count, and short words get one edit rather than two; that keeps fix:,
+// it: and add: reading as text.
+func fieldTypo(s string) string {
+	if len(s) < 3 {
+		return ""
+	}
+	maxDist := 2
+	if len(s) <= 4 {
+		maxDist = 1
+	}
+	var candidates []string
+	for name := range filterFields {
+		if name[0] == s[0] && editDistance(s, name) <= maxDist {
+			candidates = append(candidates, name)
+		}
+	}
+	sort.Strings(candidates)
+	return closest(s, candidates)
+}
+
+// prSearchText is what a free-text term is matched against as a plain
+// substring, on top of the fuzzy match, so numbers, statuses and reviewers
+// still find PRs.
+func prSearchText(pr PR) string {
+	return strings.ToLower(fmt.Sprintf("%s %s %s %s %s %s #%d %d %s",
+		pr.Repository.Name, pr.Title, pr.Author.Login, pr.HeadRefName, statusLabelForFilter(pr),
+		pr.Repository.Owner.Login, pr.Number, pr.Number, getAllReviewerNames(pr)))
+}
+
+// resolveUser expands @me to the signed-in user.
+func resolveUser(v string) string {
+	if v == "@me" {
+		return strings.ToLower(currentUser)
+	}
+	return strings.TrimPrefix(v, "@")
+}
+
+func repoPredicate(v string) (predExpr, error) {
+	return func(pr PR) bool {
+		name := strings.ToLower(pr.Repository.Name)
+		return v == name || v == strings.ToLower(pr.Repository.Owner.Login)+"/"+name
+	}, nil
+}
+
+func orgPredicate(v string) (predExpr, error) {
+	return func(pr PR) bool {
+		return strings.EqualFold(pr.Repository.Owner.Login, v)
+	}, nil
+}
+
+func authorPredicate(v string) (predExpr, error) {
+	return func(pr PR) bool {
+		return strings.EqualFold(pr.Author.Login, resolveUser(v))
+	}, nil
+}
+
+func isPredicate(v string) (predExpr, error) {
+	for _, s := range statusFilters {
+		if v == s {
+			return func(pr PR) bool { return statusLabelForFilter(pr) == v }, nil
+		}
+	}
+	return nil, fmt.Errorf("is:%s: want one of %s", v, strings.Join(statusFilters, ", "))
+}
+
+// reviewPredicate takes GitHub's review states (none, required, approved,
+// changes_requested) or a user whose review is requested (review:@me).
+func reviewPredicate(v string) (predExpr, error) {
+	switch v {
+	case "none":
+		return func(pr PR) bool { return pr.ReviewDecision == "" }, nil
+	case "required", "approved", "changes_requested":
+		decision := strings.ToUpper(v)
+		if v == "required" {
+			decision = "REVIEW_REQUIRED"
+		}
+		return func(pr PR) bool { return pr.ReviewDecision == decision }, nil
+	}
+	return func(pr PR) bool {
+		user := resolveUser(v)
+		for _, rr := range pr.ReviewRequests.Nodes {
+			if strings.EqualFold(rr.RequestedReviewer.Login, user) || strings.EqualFold(rr.RequestedReviewer.Name, user) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+func reviewedByPredicate(v string) (predExpr, error) {
+	return func(pr PR) bool {
+		user := resolveUser(v)
+		for _, r := range pr.Reviews.Nodes {
+			if strings.EqualFold(r.Author.Login, user) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+func labelPredicate(v string) (predExpr, error) {
+	return func(pr PR) bool {
+		for _, l := range pr.Labels.Nodes {
+			if strings.EqualFold(l.Name, v) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// sizePredicate compares additions+deletions: size:>500, size:<=20,
+// size:100..400.
+func sizePredicate(v string) (predExpr, error) {
+	cmp, err := parseRange(v, func(s string) (int64, error) {
+		return strconv.ParseInt(s, 10, 64)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("size:%s: %v", v, err)
+	}
+	return func(pr PR) bool { return cmp(int64(pr.Additions + pr.Deletions)) }, nil
+}
+
+// agePredicate compares time since the PR was opened: age:>7d, age:<12h,
+// age:1w..4w. A bare number means days.
+func agePredicate(v string) (predExpr, error) {
+	cmp, err := parseRange(v, parseAge)
+	if err != nil {
+		return nil, fmt.Errorf("age:%s: %v", v, err)
+	}
+	return func(pr PR) bool {
+		if pr.CreatedAt.IsZero() {
+			return false
+		}
+		return cmp(int64(time.Since(pr.CreatedAt)))
+	}, nil
+}
+
+func parseAge(s string) (int64, error) {
+	units := map[byte]time.Duration{'h': time.Hour, 'd': 24 * time.Hour, 'w': 7 * 24 * time.Hour, 'y': 365 * 24 * time.Hour}
+	unit := 24 * time.Hour
+	if s != "" {
+		if u, ok := units[s[len(s)-1]]; ok {
+			unit = u
+			s = s[:len(s)-1]
+		}
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("want a duration like 7d, 12h or 2w")
+	}
+	return int64(n * float64(unit)), nil
+}
+
+func ciPredicate(v string) (predExpr, error) {
+	if v == "none" {
+		return func(pr PR) bool { return ciState(pr) == "" }, nil
+	}
+	for _, s := range []string{"passing", "failing", "pending"} {
+		if strings.HasPrefix(s, v) {
+			return func(pr PR) bool { return ciState(pr) == s }, nil
+		}
+	}
+	return nil, fmt.Errorf("ci:%s: want passing, failing, pending or none", v)
+}
+
+// parseRange parses a comparison (>n, >=n, <n, <=n, n) or an inclusive
+// range (lo..hi, with * for an open end) into a predicate on values.
+func parseRange(v string, parse func(string) (int64, error)) (func(int64) bool, error) {
+	if lo, hi, ok := strings.Cut(v, ".."); ok {
+		from, to := int64(-1<<63), int64(1<<63-1)
+		var err error
+		if lo != "*" {
+			if from, err = parse(lo); err != nil {
+				return nil, err
+			}
+		}
+		if hi != "*" {
+			if to, err = parse(hi); err != nil {
+				return nil, err
+			}
+		}
+		return func(n int64) bool { return n >= from && n <= to }, nil
+	}
+	for _, op := range []string{">=", "<=", ">", "<", "="} {
+		rest, ok := strings.CutPrefix(v, op)
+		if !ok {
+			continue
+		}
+		n, err := parse(rest)
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case ">=":
+			return func(x int64) bool { return x >= n }, nil
+		case "<=":
+			return func(x int64) bool { return x <= n }, nil
+		case ">":
+			return func(x int64) bool { return x > n }, nil
+		case "<":
+			return func(x int64) bool { return x < n }, nil
+		}
+		return func(x int64) bool { return x == n }, nil
+	}
+	n, err := parse(v)
+	if err != nil {
+		return nil, err
+	}
+	return func(x int64) bool { return x == n }, nil
+}
+
+// toggleFilterTerm adds term to a query, or removes it if it's already
+// there. The a and r keys use it so their filters compose with whatever
+// is typed.
+func toggleFilterTerm(q, term string) string {
+	fields := strings.Fields(q)
+	for i, f := range fields {
+		if f == term {
+			return strings.Join(append(fields[:i], fields[i+1:]...), " ")
+		}
+	}
+	return appendFilterTerm(q, term)
+}
+
+// appendFilterTerm ANDs term onto q, parenthesising q if it's an OR so the
+// new term applies to all of it.
+func appendFilterTerm(q, term string) string {
+	q = strings.TrimSpace(q)
+	if q == "" {
+		return term
+	}
+	if expr, err := parseFilter(q); err == nil {
+		if _, isOr := expr.(orExpr); isOr {
+			q = "(" + q + ")"
+		}
+	}
+	return q + " " + term
+}
+
+// cycleStatusTerm steps the query's is:<status> term through statusFilters
+// and then off again. A negated one (-is:draft, NOT is:draft) is stepped on
+// from the same place, as adding is:approved beside it would match nothing.
+func cycleStatusTerm(q string) string {
+	fields := strings.Fields(q)
+	for i, f := range fields {
+		status, ok := strings.CutPrefix(strings.TrimPrefix(f, "-"), "is:")
+		if !ok {
+			continue
+		}
+		for j, s := range statusFilters {
+			if s != status {
+				continue
+			}
+			start := i
+			if i > 0 && fields[i-1] == "NOT" {
+				start = i - 1
+			}
+			var next []string
+			if j+1 < len(statusFilters) {
+				next = []string{"is:" + statusFilters[j+1]}
+			}
+			fields = append(append(fields[:start:start], next...), fields[i+1:]...)
+			return strings.Join(fields, " ")
+		}
+	}
+	return appendFilterTerm(q, "is:"+statusFilters[0])
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseFilter(t *testing.T) {
+	withLabel := func(pr PR, label string) PR {
+		pr.Labels.Nodes = append(pr.Labels.Nodes, struct {
+			Name string `json:"name"`
+		}{label})
+		return pr
+	}
+	draft := testPR(3, "Draft: dark mode")
+	draft.IsDraft = true
+	prs := []PR{
+		withLabel(testPR(1, "feat: login page"), "bug"),
+		testPR(2, "Fix crash on start"),
+		draft,
+	}
+
+	tests := []struct {
+		query string
+		want  []int // PR numbers matched
+	}{
+		{"", []int{1, 2, 3}},
+		{"crash", []int{2}},
+		{"feat: login", []int{1}}, // feat: is a word, not a field
+		{"fix:", nil},
+		{"label:bug", []int{1}},
+		{"-label:bug", []int{2, 3}},
+		{"is:draft | crash", []int{2, 3}},
+		{"NOT (is:draft OR crash)", []int{1}},
+		{`"dark mode"`, []int{3}},
+	}
+	for _, tt := range tests {
+		expr, err := parseFilter(tt.query)
+		if err != nil {
+			t.Errorf("parseFilter(%q): %v", tt.query, err)
+			continue
+		}
+		var got []int
+		for _, pr := range filterPRs(prs, expr) {
+			got = append(got, pr.Number)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%q matched %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestFilterSort(t *testing.T) {
+	tests := []struct{ query, want string }{
+		{"crash", ""},
+		{"sort:created", "created"},
+		{"crash sort:-updated", "-updated"},
+		{"(is:draft | crash) (sort:size)", "size"},
+	}
+	for _, tt := range tests {
+		expr, err := parseFilter(tt.query)
+		if err != nil {
+			t.Errorf("parseFilter(%q): %v", tt.query, err)
+			continue
+		}
+		got := ""
+		if spec := filterSort(expr); spec != nil {
+			got = spec.String()
+		}
+		if got != tt.want {
+			t.Errorf("filterSort(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	tests := []struct{ query, want string }{
+		{"lable:bug", `did you mean "label:"`},
+		{"siz:<10", `did you mean "size:"`},
+		{"is:nope", "want one of"},
+		{"label:", "needs a value"},
+		{"(crash", "missing )"},
+		{"crash AND", "needs a term after it"},
+		{"| crash", "expected a term"},
+		{"sort:updated | crash", "can't be part of an OR"},
+		{"crash | (is:draft sort:size)", "can't be part of an OR"},
+		{"-sort:size", "can't be negated"},
+	}
+	for _, tt := range tests {
+		_, err := parseFilter(tt.query)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("parseFilter(%q) = %v, want an error containing %q", tt.query, err, tt.want)
+		}
+	}
+}
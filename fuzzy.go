@@ -0,0 +1,265 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Free-text filter terms are matched fzf-style: the term's characters must
+// appear in order in the repo, title, branch or author, and matches that
+// are contiguous or start at word boundaries score higher. Quoted terms
+// ("dark mode") are matched as exact substrings instead.
+
+const (
+	scoreMatch       = 16
+	bonusBoundary    = 8 // first rune, or after / - _ . space etc.
+	bonusCamel       = 6 // lower→Upper transition, e.g. the A in webApp
+	bonusConsecutive = 6
+	penaltyGap       = 1 // per skipped rune inside the match window
+)
+
+// fuzzyFields are the columns text terms are scored and highlighted in, in
+// the order highlights reports positions for them.
+func fuzzyFields(pr PR) [4]string {
+	return [4]string{pr.Repository.Name, pr.Title, pr.HeadRefName, pr.Author.Login}
+}
+
+// lowerRunes lowercases rune by rune, so indexes line up with []rune(s).
+func lowerRunes(s string) []rune {
+	rs := []rune(s)
+	for i, r := range rs {
+		rs[i] = unicode.ToLower(r)
+	}
+	return rs
+}
+
+// fuzzyMatch reports whether pattern (already lowercase) is a subsequence
+// of text, with a score and the matched rune positions. It picks the
+// shortest window ending at the first complete match, like fzf's v1
+// algorithm: cheap, and good enough for a few hundred PRs.
+func fuzzyMatch(pattern []rune, text string) (int, []int, bool) {
+	if len(pattern) == 0 {
+		return 0, nil, true
+	}
+	orig := []rune(text)
+	t := lowerRunes(text)
+
+	pi, end := 0, -1
+	for i, r := range t {
+		if r == pattern[pi] {
+			if pi++; pi == len(pattern) {
+				end = i
+				break
+			}
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+	start := end
+	for i, pi := end, len(pattern)-1; i >= 0; i-- {
+		if t[i] == pattern[pi] {
+			if pi--; pi < 0 {
+				start = i
+				break
+			}
+		}
+	}
+
+	positions := make([]int, 0, len(pattern))
+	score, prev := 0, -2
+	for i, pi := start, 0; i <= end && pi < len(pattern); i++ {
+		if t[i] != pattern[pi] {
+			continue
+		}
+		s := scoreMatch
+		switch {
+		case i == 0 || isWordBoundary(orig[i-1]):
+			s += bonusBoundary
+		case unicode.IsUpper(orig[i]) && unicode.IsLower(orig[i-1]):
+			s += bonusCamel
+		}
+		if i == prev+1 {
+			s += bonusConsecutive
+		}
+		score += s
+		positions = append(positions, i)
+		prev = i
+		pi++
+	}
+	score -= (end - start + 1 - len(pattern)) * penaltyGap
+	return score, positions, true
+}
+
+func isWordBoundary(r rune) bool {
+	return unicode.IsSpace(r) || strings.ContainsRune("/-_.:#()[]", r)
+}
+
+// substringPositions returns the rune positions of the first occurrence of
+// pattern (already lowercase) in text, or nil.
+func substringPositions(pattern []rune, text string) []int {
+	t := lowerRunes(text)
+outer:
+	for i := 0; i+len(pattern) <= len(t); i++ {
+		for j, r := range pattern {
+			if t[i+j] != r {
+				continue outer
+			}
+		}
+		positions := make([]int, len(pattern))
+		for j := range positions {
+			positions[j] = i + j
+		}
+		return positions
+	}
+	return nil
+}
+
+// textExpr is a free-text filter term.
+type textExpr struct {
+	pattern []rune // lowercase
+	exact   bool   // quoted: substring only, no fuzzy matching
+}
+
+func (e textExpr) match(pr PR) bool {
+	if strings.Contains(prSearchText(pr), string(e.pattern)) {
+		return true
+	}
+	if e.exact {
+		return false
+	}
+	_, ok := e.score(pr)
+	return ok
+}
+
+// score is the term's best fuzzy score across fuzzyFields.
+func (e textExpr) score(pr PR) (int, bool) {
+	best, found := 0, false
+	for _, f := range fuzzyFields(pr) {
+		var s int
+		var ok bool
+		if e.exact {
+			ok = substringPositions(e.pattern, f) != nil
+			s = len(e.pattern) * (scoreMatch + bonusConsecutive)
+		} else {
+			s, _, ok = fuzzyMatch(e.pattern, f)
+		}
+		if ok && (!found || s > best) {
+			best, found = s, true
+		}
+	}
+	return best, found
+}
+
+// textTerms collects the free-text terms that can make a PR match — not
+// the negated ones, which never contribute a match to highlight.
+func textTerms(expr filterExpr) []textExpr {
+	switch e := expr.(type) {
+	case textExpr:
+		return []textExpr{e}
+	case andExpr:
+		var out []textExpr
+		for _, x := range e {
+			out = append(out, textTerms(x)...)
+		}
+		return out
+	case orExpr:
+		var out []textExpr
+		for _, x := range e {
+			out = append(out, textTerms(x)...)
+		}
+		return out
+	}
+	return nil
+}
+
+// rankPRs stably sorts prs best match first by the summed score of terms.
+func rankPRs(prs []PR, terms []textExpr) {
+	if len(terms) == 0 {
+		return
+	}
+	scores := make(map[string]int, len(prs))
+	for _, pr := range prs {
+		total := 0
+		for _, t := range terms {
+			if s, ok := t.score(pr); ok {
+				total += s
+			}
+		}
+		scores[prKey(pr)] = total
+	}
+	sort.SliceStable(prs, func(i, j int) bool {
+		return scores[prKey(prs[i])] > scores[prKey(prs[j])]
+	})
+}
+
+// highlights returns, for each of fuzzyFields, the rune positions any term
+// matched.
+func highlights(pr PR, terms []textExpr) [4][]int {
+	var out [4][]int
+	for i, f := range fuzzyFields(pr) {
+		for _, t := range terms {
+			var positions []int
+			if t.exact {
+				positions = substringPositions(t.pattern, f)
+			} else {
+				_, positions, _ = fuzzyMatch(t.pattern, f)
+			}
+			out[i] = append(out[i], positions...)
+		}
+	}
+	return out
+}
+
+// highlightCell renders text the way pad(truncate(text, width-1), width)
+// lays it out, styling the runes at positions with hl and the rest with
+// base. Positions cut off by truncation are dropped.
+func highlightCell(text string, width int, positions []int, base, hl lipgloss.Style) string {
+	if len(positions) == 0 {
+		return base.Render(pad(truncate(text, width-1), width))
+	}
+	runes := []rune(text)
+	keep, ellipsis := len(runes), ""
+	if displayWidth(text) > width-1 {
+		keep, ellipsis = 0, "..."
+		for i := len(runes); i > 0; i-- {
+			if displayWidth(string(runes[:i])+ellipsis) <= width-1 {
+				keep = i
+				break
+			}
+		}
+	}
+	marked := make(map[int]bool, len(positions))
+	for _, p := range positions {
+		marked[p] = true
+	}
+
+	var b strings.Builder
+	var run []rune
+	runMarked := false
+	flush := func() {
+		if len(run) == 0 {
+			return
+		}
+		style := base
+		if runMarked {
+			style = hl
+		}
+		b.WriteString(style.Render(string(run)))
+		run = run[:0]
+	}
+	for i := 0; i < keep; i++ {
+		if marked[i] != runMarked {
+			flush()
+			runMarked = marked[i]
+		}
+		run = append(run, runes[i])
+	}
+	flush()
+	used := displayWidth(string(runes[:keep]) + ellipsis)
+	b.WriteString(base.Render(ellipsis + strings.Repeat(" ", max(0, width-used))))
+	return b.String()
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		pattern, text string
+		want          []int // matched positions; nil for no match
+	}{
+		{"", "anything", []int{}},
+		{"authflw", "feature/auth-flow", []int{8, 9, 10, 11, 13, 14, 16}},
+		{"api", "Fix API docs", []int{4, 5, 6}},
+		{"ab", "a-x-a-b", []int{4, 6}}, // the shortest window ending at the first match
+		{"zz", "fuzzy", []int{2, 3}},
+		{"xyz", "feature/auth-flow", nil},
+		{"ba", "ab", nil}, // in order only
+	}
+	for _, tt := range tests {
+		_, got, ok := fuzzyMatch([]rune(tt.pattern), tt.text)
+		if ok != (tt.want != nil) || fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("fuzzyMatch(%q, %q) = %v %v, want %v", tt.pattern, tt.text, got, ok, tt.want)
+		}
+	}
+}
+
+func TestFuzzyMatchScores(t *testing.T) {
+	// Each pair: the first text should score higher for the pattern.
+	tests := []struct{ pattern, better, worse string }{
+		{"auth", "author", "xaxuxtxh"},    // consecutive
+		{"fl", "auth-flow", "refill"},     // word boundary
+		{"ab", "xabx", "xaxxxxxxb"},       // fewer gaps
+		{"ab", "fooBar/ab", "fooBar/xab"}, // boundary over camel case
+	}
+	for _, tt := range tests {
+		b, _, _ := fuzzyMatch([]rune(tt.pattern), tt.better)
+		w, _, _ := fuzzyMatch([]rune(tt.pattern), tt.worse)
+		if b <= w {
+			t.Errorf("%q: %q scored %d, not above %q's %d", tt.pattern, tt.better, b, tt.worse, w)
+		}
+	}
+}
+
+func TestRankPRs(t *testing.T) {
+	prs := []PR{
+		testPR(1, "Refill the cache"),
+		testPR(2, "Fix login flow"),
+		testPR(3, "Unrelated"),
+		testPR(4, "Flow control"),
+	}
+	tests := []struct {
+		query string
+		want  []int
+	}{
+		{"flow", []int{2, 4, 1, 3}},    // whole-word matches first, ties in order
+		{"fl", []int{4, 2, 1, 3}},      // the first complete match decides: Fix login is a gappy f…l
+		{`"login"`, []int{2, 1, 3, 4}}, // exact terms score only where they match
+		{"", []int{1, 2, 3, 4}},        // no terms: order untouched
+	}
+	for _, tt := range tests {
+		expr, err := parseFilter(tt.query)
+		if err != nil {
+			t.Fatalf("parseFilter(%q): %v", tt.query, err)
+		}
+		got := append([]PR(nil), prs...)
+		rankPRs(got, textTerms(expr))
+		var numbers []int
+		for _, pr := range got {
+			numbers = append(numbers, pr.Number)
+		}
+		if fmt.Sprint(numbers) != fmt.Sprint(tt.want) {
+			t.Errorf("rankPRs(%q) = %v, want %v", tt.query, numbers, tt.want)
+		}
+	}
+}
+
+func TestHighlightCell(t *testing.T) {
+	base := lipgloss.NewStyle()
+	hl := lipgloss.NewStyle().Transform(strings.ToUpper)
+	tests := []struct {
+		text      string
+		width     int
+		positions []int
+		want      string
+	}{
+		{"auth-flow", 12, []int{0, 1, 5}, "AUth-Flow   "},
+		{"auth-flow", 12, nil, "auth-flow   "},
+		{"feature/auth-flow", 10, []int{0, 8, 9}, "Featur... "}, // cut-off positions are dropped
+		{"日本語", 8, []int{1}, "日本語  "},
+	}
+	for _, tt := range tests {
+		if got := highlightCell(tt.text, tt.width, tt.positions, base, hl); got != tt.want {
+			t.Errorf("highlightCell(%q, %d, %v) = %q, want %q", tt.text, tt.width, tt.positions, got, tt.want)
+		}
+		if got := displayWidth(highlightCell(tt.text, tt.width, tt.positions, base, hl)); got != tt.width {
+			t.Errorf("highlightCell(%q, %d) is %d wide", tt.text, tt.width, got)
+		}
+	}
+}
@@ -0,0 +1,352 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// githubBackend talks to one GitHub host over GraphQL for reads and shells
+// out to `gh` for the operations it already does well (diffs, reviews).
+type githubBackend struct {
+	host string
+
+	mu    sync.Mutex
+	token string // cached `gh auth token` for direct HTTP calls
+}
+
+func newGitHubBackend(host string) *githubBackend {
+	return &githubBackend{host: host}
+}
+
+// graphqlURL is api.github.com for github.com and /api/graphql on GHES.
+func (b *githubBackend) graphqlURL() string {
+	if b.host == githubDotCom {
+		return "https://api.github.com/graphql"
+	}
+	return "https://" + b.host + "/api/graphql"
+}
+
+func (b *githubBackend) tokenCachePath() string {
+	if b.host == githubDotCom {
+		return filepath.Join(cacheDir(), "token")
+	}
+	return filepath.Join(cacheDir(), "token-"+b.host)
+}
+
+func (b *githubBackend) loadToken() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.token != "" {
+		return nil
+	}
+	if data, err := os.ReadFile(b.tokenCachePath()); err == nil {
+		if t := strings.TrimSpace(string(data)); t != "" {
+			b.token = t
+			return nil
+		}
+	}
+	return b.refreshTokenLocked()
+}
+
+func (b *githubBackend) refreshToken() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.refreshTokenLocked()
+}
+
+func (b *githubBackend) refreshTokenLocked() error {
+	out, err := exec.Command("gh", "auth", "token", "--hostname", b.host).Output()
+	if err != nil {
+		return err
+	}
+	t := strings.TrimSpace(string(out))
+	if t == "" {
+		return fmt.Errorf("empty token from gh auth token --hostname %s", b.host)
+	}
+	b.token = t
+	_ = os.WriteFile(b.tokenCachePath(), []byte(t), 0600)
+	return nil
+}
+
+func (b *githubBackend) invalidateToken() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_ = os.Remove(b.tokenCachePath())
+	b.token = ""
+}
+
+func (b *githubBackend) SearchPRs(query, after string) (prPage, error) {
+	vars := map[string]any{"q": query, "after": nil}
+	if after != "" {
+		vars["after"] = after
+	}
+	var data struct {
+		Search struct {
+			PageInfo struct {
+				EndCursor   string `json:"endCursor"`
+				HasNextPage bool   `json:"hasNextPage"`
+			} `json:"pageInfo"`
+			Nodes []PR `json:"nodes"`
+		} `json:"search"`
+	}
+	if err := b.query(searchPRsQuery, vars, &data); err != nil {
+		return prPage{}, err
+	}
+	for i := range data.Search.Nodes {
+		data.Search.Nodes[i].Host = b.host
+	}
+	return prPage{
+		PRs:       data.Search.Nodes,
+		EndCursor: data.Search.PageInfo.EndCursor,
+		HasNext:   data.Search.PageInfo.HasNextPage,
+	}, nil
+}
+
+func (b *githubBackend) FetchPR(owner, repo string, number int) (PR, error) {
+	vars := map[string]any{"owner": owner, "name": repo, "number": number}
+	var data struct {
+		Repository *struct {
+			PullRequest *PR `json:"pullRequest"`
+		} `json:"repository"`
+	}
+	if err := b.query(pullRequestQuery, vars, &data); err != nil {
+		return PR{}, err
+	}
+	if data.Repository == nil || data.Repository.PullRequest == nil {
+		return PR{}, fmt.Errorf("PR %s/%s#%d not found", owner, repo, number)
+	}
+	pr := *data.Repository.PullRequest
+	pr.Host = b.host
+	return pr, nil
+}
+
+// FetchPRs refreshes a chunk of PRs with a single aliased query
+// (pr0: repository(...) { pullRequest(...) }, pr1: ...). PRs that have been
+// deleted or made inaccessible come back as per-alias errors; the rest are
+// still returned.
+func (b *githubBackend) FetchPRs(prs []PR) ([]PR, error) {
+	if len(prs) == 0 {
+		return nil, nil
+	}
+	vars := make(map[string]any, len(prs)*3)
+	for i, pr := range prs {
+		vars[fmt.Sprintf("o%d", i)] = pr.Repository.Owner.Login
+		vars[fmt.Sprintf("n%d", i)] = pr.Repository.Name
+		vars[fmt.Sprintf("p%d", i)] = pr.Number
+	}
+	var data map[string]json.RawMessage
+	qerr := b.query(batchPRsQuery(len(prs)), vars, &data)
+	if qerr != nil && data == nil {
+		return nil, qerr
+	}
+
+	var out []PR
+	missing := 0
+	for i, orig := range prs {
+		var alias struct {
+			PullRequest *PR `json:"pullRequest"`
+		}
+		raw, ok := data[fmt.Sprintf("pr%d", i)]
+		if !ok || json.Unmarshal(raw, &alias) != nil || alias.PullRequest == nil {
+			missing++
+			continue
+		}
+		pr := *alias.PullRequest
+		pr.Host = b.host
+		pr.Repository = orig.Repository
+		out = append(out, pr)
+	}
+	if missing > 0 {
+		if qerr != nil {
+			return out, fmt.Errorf("%d of %d PRs: %w", missing, len(prs), qerr)
+		}
+		return out, fmt.Errorf("%d of %d PRs missing from batch", missing, len(prs))
+	}
+	return out, nil
+}
+
+func (b *githubBackend) BranchPRs(owner, repo string, branches []string) (map[string]PR, error) {
+	out := map[string]PR{}
+	for start := 0; start < len(branches); start += refreshBatchSize {
+		chunk := branches[start:min(start+refreshBatchSize, len(branches))]
+		vars := map[string]any{"owner": owner, "name": repo}
+		for i, branch := range chunk {
+			vars[fmt.Sprintf("b%d", i)] = branch
+		}
+		var data struct {
+			Repository map[string]struct {
+				Nodes []PR `json:"nodes"`
+			} `json:"repository"`
+		}
+		if err := b.query(branchPRsQuery(len(chunk)), vars, &data); err != nil {
+			return out, err
+		}
+		if data.Repository == nil {
+			return out, fmt.Errorf("repository %s/%s not found", owner, repo)
+		}
+		for i, branch := range chunk {
+			if nodes := data.Repository[fmt.Sprintf("b%d", i)].Nodes; len(nodes) > 0 {
+				pr := nodes[0]
+				pr.Host = b.host
+				out[branch] = pr
+			}
+		}
+	}
+	return out, nil
+}
+
+func (b *githubBackend) FetchPRDetail(pr PR) (PRDetail, error) {
+	vars := map[string]any{"owner": pr.Repository.Owner.Login, "name": pr.Repository.Name, "number": pr.Number}
+	var data struct {
+		Repository *struct {
+			PullRequest *PRDetail `json:"pullRequest"`
+		} `json:"repository"`
+	}
+	if err := b.query(pullRequestDetailQuery, vars, &data); err != nil {
+		return PRDetail{}, err
+	}
+	if data.Repository == nil || data.Repository.PullRequest == nil {
+		return PRDetail{}, fmt.Errorf("PR %s not found", prKey(pr))
+	}
+	return *data.Repository.PullRequest, nil
+}
+
+func (b *githubBackend) MergeInfo(pr PR) (mergeInfo, error) {
+	vars := map[string]any{"owner": pr.Repository.Owner.Login, "name": pr.Repository.Name, "number": pr.Number}
+	var data struct {
+		Repository *struct {
+			MergeCommitAllowed  bool `json:"mergeCommitAllowed"`
+			SquashMergeAllowed  bool `json:"squashMergeAllowed"`
+			RebaseMergeAllowed  bool `json:"rebaseMergeAllowed"`
+			AutoMergeAllowed    bool `json:"autoMergeAllowed"`
+			DeleteBranchOnMerge bool `json:"deleteBranchOnMerge"`
+			PullRequest         *struct {
+				ID                       string `json:"id"`
+				Mergeable                string `json:"mergeable"`
+				MergeStateStatus         string `json:"mergeStateStatus"`
+				IsCrossRepository        bool   `json:"isCrossRepository"`
+				ViewerCanMergeAsAdmin    bool   `json:"viewerCanMergeAsAdmin"`
+				ViewerCanEnableAutoMerge bool   `json:"viewerCanEnableAutoMerge"`
+				HeadRef                  *struct {
+					ID string `json:"id"`
+				} `json:"headRef"`
+			} `json:"pullRequest"`
+		} `json:"repository"`
+	}
+	if err := b.query(mergeInfoQuery, vars, &data); err != nil {
+		return mergeInfo{}, err
+	}
+	if data.Repository == nil || data.Repository.PullRequest == nil {
+		return mergeInfo{}, fmt.Errorf("PR %s not found", prKey(pr))
+	}
+	repo, p := data.Repository, data.Repository.PullRequest
+	info := mergeInfo{
+		PullRequestID:            p.ID,
+		Mergeable:                p.Mergeable,
+		MergeStateStatus:         p.MergeStateStatus,
+		AutoMergeAllowed:         repo.AutoMergeAllowed,
+		ViewerCanEnableAutoMerge: p.ViewerCanEnableAutoMerge,
+		ViewerCanMergeAsAdmin:    p.ViewerCanMergeAsAdmin,
+		DeleteBranchOnMerge:      repo.DeleteBranchOnMerge,
+	}
+	if p.HeadRef != nil && !p.IsCrossRepository {
+		info.HeadRefID = p.HeadRef.ID
+	}
+	if repo.MergeCommitAllowed {
+		info.Methods = append(info.Methods, "MERGE")
+	}
+	if repo.SquashMergeAllowed {
+		info.Methods = append(info.Methods, "SQUASH")
+	}
+	if repo.RebaseMergeAllowed {
+		info.Methods = append(info.Methods, "REBASE")
+	}
+	return info, nil
+}
+
+// MergePR merges (or enables auto-merge on) a PR, then deletes its head
+// branch if asked. Auto-merge leaves branch deletion to the repo setting,
+// since the merge hasn't happened yet.
+func (b *githubBackend) MergePR(pr PR, opts mergeOptions) error {
+	info, err := b.MergeInfo(pr)
+	if err != nil {
+		return err
+	}
+	vars := map[string]any{"id": info.PullRequestID, "method": opts.Method}
+	if opts.Auto {
+		return b.mutate(enableAutoMergeMutation, vars, nil)
+	}
+	if err := b.mutate(mergePullRequestMutation, vars, nil); err != nil {
+		return err
+	}
+	if opts.DeleteBranch && info.HeadRefID != "" && !info.DeleteBranchOnMerge {
+		if err := b.mutate(deleteRefMutation, map[string]any{"id": info.HeadRefID}, nil); err != nil {
+			return fmt.Errorf("merged, but deleting %s failed: %w", pr.HeadRefName, err)
+		}
+	}
+	return nil
+}
+
+func (b *githubBackend) FetchDiff(pr PR) ([]byte, error) {
+	return runGH("pr", "diff", fmt.Sprintf("%d", pr.Number), "--repo", repoSlug(pr))
+}
+
+func (b *githubBackend) SubmitReview(pr PR, action, body string) error {
+	args := []string{"pr", "review", fmt.Sprintf("%d", pr.Number), "--repo", repoSlug(pr)}
+	switch action {
+	case "approve":
+		args = append(args, "--approve")
+	case "request-changes":
+		args = append(args, "--request-changes", "--body", body)
+	case "comment":
+		args = append(args, "--comment", "--body", body)
+	}
+	_, err := runGH(args...)
+	return err
+}
+
+func (b *githubBackend) CurrentUser() (string, error) {
+	out, err := exec.Command("gh", "api", "--hostname", b.host, "user", "--jq", ".login").Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func (b *githubBackend) Orgs() ([]string, error) {
+	out, err := exec.Command("gh", "api", "--hostname", b.host, "user/orgs", "--jq", ".[].login").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch orgs: %w", err)
+	}
+
+	var result []string
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			result = append(result, line)
+		}
+	}
+	return result, nil
+}
+
+// runGH runs a gh subcommand and returns stdout. On failure the error carries
+// gh's stderr, which is far more useful in the status line than an exit code.
+func runGH(args ...string) ([]byte, error) {
+	cmd := exec.Command("gh", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return nil, fmt.Errorf("%s", msg)
+	}
+	return out, nil
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// prFields is the one PR selection shared by every query that returns PRs,
+// so search results and single-PR refreshes always decode into the same shape.
+const prFields = `
+fragment prFields on PullRequest {
+	number
+	title
+	headRefName
+	baseRefName
+	state
+	isDraft
+	additions
+	deletions
+	createdAt
+	updatedAt
+	author { login }
+	repository { name owner { login } }
+	labels(first: 10) { nodes { name } }
+	comments { totalCount }
+	reviewDecision
+	reviewRequests(first: 5) { totalCount nodes { requestedReviewer { ... on User { login } ... on Team { name } } } }
+	reviews(last: 5) { nodes { author { login } state } }
+	commits(last: 1) {
+		nodes {
+			commit {
+				statusCheckRollup {
+					state
+					contexts(first: 50) {
+						nodes {
+							__typename
+							... on CheckRun { name status conclusion detailsUrl }
+							... on StatusContext { context state targetUrl }
+						}
+					}
+				}
+			}
+		}
+	}
+}`
+
+const searchPRsQuery = `
+query SearchPRs($q: String!, $after: String) {
+	rateLimit { remaining resetAt cost }
+	search(query: $q, type: ISSUE, first: 50, after: $after) {
+		pageInfo { endCursor hasNextPage }
+		nodes { ... on PullRequest { ...prFields } }
+	}
+}` + prFields
+
+const pullRequestQuery = `
+query PullRequest($owner: String!, $name: String!, $number: Int!) {
+	rateLimit { remaining resetAt cost }
+	repository(owner: $owner, name: $name) {
+		pullRequest(number: $number) { ...prFields }
+	}
+}` + prFields
+
+// pullRequestDetailQuery backs the detail pane. Unlike prFields it pulls the
+// whole review history and every requested reviewer, so it's only run for
+// the PR under the cursor.
+const pullRequestDetailQuery = `
+query PullRequestDetail($owner: String!, $name: String!, $number: Int!) {
+	rateLimit { remaining resetAt cost }
+	repository(owner: $owner, name: $name) {
+		pullRequest(number: $number) {
+			body
+			baseRefName
+			headRefName
+			createdAt
+			updatedAt
+			mergeable
+			mergeStateStatus
+			milestone { title }
+			labels(first: 20) { nodes { name } }
+			assignees(first: 20) { nodes { login } }
+			reviewRequests(first: 100) { nodes { requestedReviewer { ... on User { login } ... on Team { name } } } }
+			reviews(first: 100) { nodes { author { login } state submittedAt } }
+		}
+	}
+}`
+
+const mergeInfoQuery = `
+query MergeInfo($owner: String!, $name: String!, $number: Int!) {
+	rateLimit { remaining resetAt cost }
+	repository(owner: $owner, name: $name) {
+		mergeCommitAllowed
+		squashMergeAllowed
+		rebaseMergeAllowed
+		autoMergeAllowed
+		deleteBranchOnMerge
+		pullRequest(number: $number) {
+			id
+			mergeable
+			mergeStateStatus
+			isCrossRepository
+			viewerCanMergeAsAdmin
+			viewerCanEnableAutoMerge
+			headRef { id }
+		}
+	}
+}`
+
+const mergePullRequestMutation = `
+mutation MergePullRequest($id: ID!, $method: PullRequestMergeMethod!) {
+	mergePullRequest(input: {pullRequestId: $id, mergeMethod: $method}) { pullRequest { state } }
+}`
+
+const enableAutoMergeMutation = `
+mutation EnableAutoMerge($id: ID!, $method: PullRequestMergeMethod!) {
+	enablePullRequestAutoMerge(input: {pullRequestId: $id, mergeMethod: $method}) { pullRequest { number } }
+}`
+
+const deleteRefMutation = `
+mutation DeleteRef($id: ID!) {
+	deleteRef(input: {refId: $id}) { clientMutationId }
+}`
+
+// batchPRsQuery builds a query fetching n PRs under aliases pr0..pr{n-1},
+// taking variables $o<i>, $n<i> and $p<i> (owner, name, number).
+func batchPRsQuery(n int) string {
+	var params, fields strings.Builder
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			params.WriteString(", ")
+		}
+		fmt.Fprintf(&params, "$o%d: String!, $n%d: String!, $p%d: Int!", i, i, i)
+		fmt.Fprintf(&fields, "\tpr%d: repository(owner: $o%d, name: $n%d) { pullRequest(number: $p%d) { ...prFields } }\n", i, i, i, i)
+	}
+	return "\nquery BatchPRs(" + params.String() + ") {\n\trateLimit { remaining resetAt cost }\n" + fields.String() + "}" + prFields
+}
+
+// branchPRsQuery builds a query for the newest PR of each of n head
+// branches in one repo, under aliases b0..b{n-1}, taking $owner, $name and
+// $b<i> (branch). It adds the head commit and repo to prFields.
+func branchPRsQuery(n int) string {
+	var params, fields strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&params, ", $b%d: String!", i)
+		fmt.Fprintf(&fields, "\t\tb%d: pullRequests(headRefName: $b%d, first: 1, orderBy: {field: CREATED_AT, direction: DESC}) { nodes { ...prFields headRefOid headRepository { name owner { login } } } }\n", i, i)
+	}
+	return "\nquery BranchPRs($owner: String!, $name: String!" + params.String() + ") {\n\trateLimit { remaining resetAt cost }\n\trepository(owner: $owner, name: $name) {\n" + fields.String() + "\t}\n}" + prFields
+}
+
+type graphqlRequest struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables,omitempty"`
+}
+
+type graphqlError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+	Path    []any  `json:"path"`
+}
+
+// graphqlErrors is the "errors" array of a GraphQL response. GitHub returns
+// these with HTTP 200, so they have to be checked separately from status.
+type graphqlErrors []graphqlError
+
+func (e graphqlErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, ge := range e {
+		msgs = append(msgs, ge.Message)
+	}
+	return "graphql: " + strings.Join(msgs, "; ")
+}
+
+// query sends a GraphQL query with variables and decodes "data" into out.
+// When the response carries errors, out still receives whatever partial data
+// came back and the errors are returned as graphqlErrors.
+func (b *githubBackend) query(query string, vars map[string]any, out any) error {
+	return b.send(query, vars, out, true)
+}
+
+// mutate is query for mutations. They aren't retried after network and
+// gateway errors, as GitHub may have applied them before the error, and a
+// second merge or branch deletion isn't harmless.
+func (b *githubBackend) mutate(mutation string, vars map[string]any, out any) error {
+	return b.send(mutation, vars, out, false)
+}
+
+func (b *githubBackend) send(doc string, vars map[string]any, out any, retryable bool) error {
+	raw, err := b.graphqlPOST(graphqlRequest{Query: doc, Variables: vars}, retryable)
+	if err != nil {
+		return err
+	}
+	var resp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors graphqlErrors   `json:"errors"`
+	}
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return fmt.Errorf("failed to parse graphql response: %w", err)
+	}
+	if len(resp.Data) > 0 && string(resp.Data) != "null" {
+		var rl struct {
+			RateLimit *struct {
+				Remaining int       `json:"remaining"`
+				ResetAt   time.Time `json:"resetAt"`
+				Cost      int       `json:"cost"`
+			} `json:"rateLimit"`
+		}
+		if json.Unmarshal(resp.Data, &rl) == nil && rl.RateLimit != nil {
+			rateLimitFor(b.host).observeQuery(rl.RateLimit.Remaining, rl.RateLimit.Cost, rl.RateLimit.ResetAt)
+		}
+	}
+	if len(resp.Data) > 0 && string(resp.Data) != "null" && out != nil {
+		if err := json.Unmarshal(resp.Data, out); err != nil {
+			return fmt.Errorf("failed to parse graphql data: %w", err)
+		}
+	}
+	if len(resp.Errors) > 0 {
+		return resp.Errors
+	}
+	return nil
+}
+
+// graphqlPOST sends a request through the shared concurrency limiter,
+// refreshing the token once on 401 and backing off on rate limits. Network
+// and gateway errors are retried too when retryable is set; GitHub may have
+// acted on the request before failing, so only queries set it.
+func (b *githubBackend) graphqlPOST(req graphqlRequest, retryable bool) ([]byte, error) {
+	if err := b.loadToken(); err != nil {
+		return nil, fmt.Errorf("no gh token for %s (run: gh auth login --hostname %s): %w", b.host, b.host, err)
+	}
+	limits := rateLimitFor(b.host)
+	refreshed := false
+	for attempt := 0; ; attempt++ {
+		if err := limits.wait(); err != nil {
+			return nil, err
+		}
+		apiSlots <- struct{}{}
+		data, status, header, err := b.graphqlPOSTOnce(req)
+		<-apiSlots
+		if err != nil {
+			if retryable && attempt < maxRetries {
+				time.Sleep(time.Second << attempt)
+				continue
+			}
+			return nil, err
+		}
+		limits.observeHeaders(header)
+
+		// Token may have rotated since we cached it — refresh once and retry.
+		if status == http.StatusUnauthorized && !refreshed {
+			refreshed = true
+			b.invalidateToken()
+			if err := b.refreshToken(); err != nil {
+				return nil, fmt.Errorf("auth refresh failed: %w", err)
+			}
+			continue
+		}
+		if !retryable && gatewayError(status) {
+			return nil, fmt.Errorf("graphql %d: GitHub may or may not have made the change; refresh to check before trying again", status)
+		}
+		if delay, ok := retryDelay(status, header, data, attempt); ok {
+			until := time.Now().Add(delay)
+			limits.throttle(until)
+			if attempt < maxRetries && delay <= maxRetryWait {
+				continue // limits.wait() sleeps until the throttle lifts
+			}
+			return nil, fmt.Errorf("rate limited until %s", until.Local().Format("15:04"))
+		}
+		if status >= 400 {
+			return nil, fmt.Errorf("graphql %d: %s", status, strings.TrimSpace(string(data)))
+		}
+		return data, nil
+	}
+}
+
+func (b *githubBackend) graphqlPOSTOnce(gr graphqlRequest) ([]byte, int, http.Header, error) {
+	body, err := json.Marshal(gr)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	req, err := http.NewRequest("POST", b.graphqlURL(), bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	b.mu.Lock()
+	token := b.token
+	b.mu.Unlock()
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, resp.Header, err
+	}
+	return data, resp.StatusCode, resp.Header, nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Hosts: sup talks to github.com unless SUP_HOST points it at a GitHub
+// Enterprise Server instance. SUP_HOSTS maps individual orgs to other hosts
+// ("acme=ghe.acme.com,infra=ghe.acme.com") for setups that span both.
+
+const githubDotCom = "github.com"
+
+// normalizeHost lowercases a host and strips any scheme or trailing slash,
+// so "https://GHE.example.com/" and "ghe.example.com" compare equal.
+func normalizeHost(h string) string {
+	h = strings.TrimSpace(strings.ToLower(h))
+	h = strings.TrimPrefix(h, "https://")
+	h = strings.TrimPrefix(h, "http://")
+	h = strings.TrimSuffix(h, "/")
+	return h
+}
+
+func defaultHost() string {
+	if h := normalizeHost(opts.Host); h != "" {
+		return h
+	}
+	return githubDotCom
+}
+
+// orgHosts parses SUP_HOSTS (or --hosts) into an org → host map. Org names
+// are lowercased.
+func orgHosts() map[string]string {
+	m := map[string]string{}
+	for _, pair := range strings.Split(opts.Hosts, ",") {
+		org, host, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		org = strings.ToLower(strings.TrimSpace(org))
+		if host = normalizeHost(host); org != "" && host != "" {
+			m[org] = host
+		}
+	}
+	return m
+}
+
+func hostForOrg(org string) string {
+	if h, ok := orgHosts()[strings.ToLower(org)]; ok {
+		return h
+	}
+	return defaultHost()
+}
+
+// knownHosts returns the default host followed by any other host mentioned
+// in SUP_HOSTS, sorted and de-duplicated.
+func knownHosts() []string {
+	def := defaultHost()
+	seen := map[string]bool{def: true}
+	var extra []string
+	for _, h := range orgHosts() {
+		if !seen[h] {
+			seen[h] = true
+			extra = append(extra, h)
+		}
+	}
+	sort.Strings(extra)
+	return append([]string{def}, extra...)
+}
+
+// prHost returns the host a PR lives on. PRs cached before hosts existed
+// carry no host and belong to github.com.
+func prHost(pr PR) string {
+	if pr.Host == "" {
+		return githubDotCom
+	}
+	return pr.Host
+}
+
+func prURL(pr PR) string {
+	return fmt.Sprintf("https://%s/%s/%s/pull/%d", prHost(pr), pr.Repository.Owner.Login, pr.Repository.Name, pr.Number)
+}
+
+// repoSlug returns OWNER/REPO, prefixed with HOST/ off github.com — the form
+// gh's --repo flag expects.
+func repoSlug(pr PR) string {
+	slug := pr.Repository.Owner.Login + "/" + pr.Repository.Name
+	if h := prHost(pr); h != githubDotCom {
+		slug = h + "/" + slug
+	}
+	return slug
+}
+
+var (
+	backendsMu sync.Mutex
+	backends   = map[string]Backend{}
+
+	// newBackend builds the backend for a host. main swaps it for the fake in
+	// demo mode; tests can do the same.
+	newBackend = func(host string) Backend { return newGitHubBackend(host) }
+)
+
+// backendFor returns the (cached) backend for host.
+func backendFor(host string) Backend {
+	if host == "" {
+		host = githubDotCom
+	}
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	b, ok := backends[host]
+	if !ok {
+		b = newBackend(host)
+		backends[host] = b
+	}
+	return b
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Keys: every key the PR list responds to is an action in this table. The
+// dispatch in handleNormalInput, the ? overlay and the footer hint all read
+// it, and the config's [keys] table rebinds actions by name:
+//
+//	[keys]
+//	refresh = "ctrl+r"
+//	open = ["o", "b"]
+//	open-all = ""   # unbind
+//
+// Prompts (approve, merge, copy, clone) and the filter line take their keys directly.
+
+// action is something a key does in the PR list.
+type action struct {
+	name    string
+	section string // heading in the ? overlay
+	help    string // consecutive actions with the same help share a row
+	keys    []string
+	footer  string // label in the footer hint, if it has one
+}
+
+var actions = []action{
+	{name: "down", section: "Navigation", help: "Move down", keys: []string{"j", "down"}},
+	{name: "up", section: "Navigation", help: "Move up", keys: []string{"k", "up"}},
+	{name: "top", section: "Navigation", help: "Top", keys: []string{"g"}},
+	{name: "bottom", section: "Navigation", help: "Bottom", keys: []string{"G"}},
+	{name: "prev-view", section: "Navigation", help: "Previous view", keys: []string{"["}},
+	{name: "next-view", section: "Navigation", help: "Next view", keys: []string{"]"}},
+	{name: "view-1", section: "Navigation", help: "Switch to view", keys: []string{"1"}},
+	{name: "view-2", section: "Navigation", help: "Switch to view", keys: []string{"2"}},
+	{name: "view-3", section: "Navigation", help: "Switch to view", keys: []string{"3"}},
+	{name: "view-4", section: "Navigation", help: "Switch to view", keys: []string{"4"}},
+	{name: "view-5", section: "Navigation", help: "Switch to view", keys: []string{"5"}},
+	{name: "view-6", section: "Navigation", help: "Switch to view", keys: []string{"6"}},
+	{name: "view-7", section: "Navigation", help: "Switch to view", keys: []string{"7"}},
+	{name: "view-8", section: "Navigation", help: "Switch to view", keys: []string{"8"}},
+	{name: "view-9", section: "Navigation", help: "Switch to view", keys: []string{"9"}},
+
+	{name: "filter", section: "Filter", help: "Open filter", keys: []string{"/"}, footer: "filter"},
+	{name: "cycle-status", section: "Filter", help: "Cycle is:<status>", keys: []string{"s"}},
+	{name: "sort", section: "Filter", help: "Cycle sort key", keys: []string{"S"}},
+	{name: "reverse-sort", section: "Filter", help: "Reverse sort", keys: []string{"i"}},
+	{name: "toggle-mine", section: "Filter", help: "Toggle author:@me", keys: []string{"a"}},
+	{name: "toggle-review", section: "Filter", help: "Toggle review:@me", keys: []string{"r"}},
+	{name: "clear", section: "Filter", help: "Clear filter (or quit)", keys: []string{"esc"}},
+
+	{name: "checkout", section: "Actions", help: "Checkout PR", keys: []string{"enter"}, footer: "checkout"},
+	{name: "checkout-worktree", section: "Actions", help: "Checkout PR in a new worktree", keys: []string{"w"}},
+	{name: "clone", section: "Actions", help: "Clone the PR's repo", keys: []string{"L"}},
+	{name: "diff", section: "Actions", help: "Review diff", keys: []string{"d"}},
+	{name: "approve", section: "Actions", help: "Approve", keys: []string{"A"}},
+	{name: "request-changes", section: "Actions", help: "Request changes", keys: []string{"D"}},
+	{name: "comment", section: "Actions", help: "Comment", keys: []string{"M"}},
+	{name: "merge", section: "Actions", help: "Merge (choose merge/squash/rebase)", keys: []string{"m"}},
+	{name: "open", section: "Actions", help: "Open in browser", keys: []string{"o"}},
+	{name: "open-all", section: "Actions", help: "Open all listed PRs", keys: []string{"O"}},
+	{name: "copy", section: "Actions", help: "Copy link, ref, branch, summary or list", keys: []string{"c"}},
+	{name: "checks", section: "Actions", help: "Show failing CI checks", keys: []string{"C"}},
+	{name: "detail", section: "Actions", help: "Toggle detail pane", keys: []string{"p"}},
+	{name: "detail-down", section: "Actions", help: "Scroll detail pane", keys: []string{"ctrl+d"}},
+	{name: "detail-up", section: "Actions", help: "Scroll detail pane", keys: []string{"ctrl+u"}},
+
+	{name: "worktrees", section: "Other", help: "Worktrees: prune merged and closed", keys: []string{"W"}},
+	{name: "refresh", section: "Other", help: "Refresh PR list", keys: []string{"R"}},
+	{name: "help", section: "Other", help: "Toggle this help", keys: []string{"?"}, footer: "help"},
+	{name: "quit", section: "Other", help: "Quit", keys: []string{"q"}, footer: "quit"},
+}
+
+// filterSyntaxHelp follows the Filter keys in the ? overlay.
+var filterSyntaxHelp = [][2]string{
+	{"repo: org: author:", "Match a field (@me is you)"},
+	{"is: review: label:", "Status, review state or requester, label"},
+	{"ci: size: age:", "CI state; size:>500, age:>7d"},
+	{"-x  OR  ( )", "Negate, either, group"},
+	{"sort:-updated", "Order by a key (overrides sort keys)"},
+}
+
+func findAction(name string) *action {
+	for i := range actions {
+		if actions[i].name == name {
+			return &actions[i]
+		}
+	}
+	return nil
+}
+
+// keyList is a [keys] value: one key, a list of keys, or "" or [] to
+// unbind the action.
+type keyList []string
+
+func (k *keyList) UnmarshalTOML(v any) error {
+	*k = keyList{}
+	switch v := v.(type) {
+	case string:
+		if v != "" {
+			*k = keyList{v}
+		}
+	case []any:
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("want a key or a list of keys, got %v", item)
+			}
+			*k = append(*k, s)
+		}
+	default:
+		return fmt.Errorf("want a key or a list of keys, got %v", v)
+	}
+	return nil
+}
+
+// keyBindings maps keys to actions and back, after [keys] overrides.
+type keyBindings struct {
+	byKey    map[string]string
+	byAction map[string][]string
+}
+
+var bindings, _ = bindKeys(nil)
+
+// actionFor returns the action bound to a key as tea reports it
+// (msg.String()), or "".
+func actionFor(key string) string {
+	return bindings.byKey[key]
+}
+
+// keysFor returns the keys bound to an action, in the order given.
+func keysFor(name string) []string {
+	return bindings.byAction[name]
+}
+
+// namedKeys are the keys other than single characters that tea reports by
+// name. ctrl+ and alt+ combinations are accepted on top of these.
+var namedKeys = map[string]bool{
+	"enter": true, "esc": true, "tab": true, "shift+tab": true, "backspace": true,
+	"delete": true, "insert": true, "up": true, "down": true, "left": true,
+	"right": true, "home": true, "end": true, "pgup": true, "pgdown": true,
+	"f1": true, "f2": true, "f3": true, "f4": true, "f5": true, "f6": true,
+	"f7": true, "f8": true, "f9": true, "f10": true, "f11": true, "f12": true,
+}
+
+// normalizeKey turns a key as written in the config into tea's spelling,
+// or "" if it isn't one.
+func normalizeKey(k string) string {
+	if k == " " || strings.EqualFold(k, "space") {
+		return " "
+	}
+	if utf8.RuneCountInString(k) == 1 {
+		return k
+	}
+	lower := strings.ToLower(k)
+	switch lower {
+	case "escape":
+		return "esc"
+	case "return":
+		return "enter"
+	}
+	if namedKeys[lower] {
+		return lower
+	}
+	for _, mod := range []string{"ctrl+", "alt+"} {
+		if strings.HasPrefix(lower, mod) {
+			if rest := normalizeKey(k[len(mod):]); rest != "" && rest != " " {
+				if mod == "ctrl+" {
+					rest = strings.ToLower(rest)
+				}
+				return mod + rest
+			}
+		}
+	}
+	return ""
+}
+
+// bindKeys applies [keys] overrides to the default bindings. A key bound to
+// two actions is an error rather than a silent last-one-wins.
+func bindKeys(overrides map[string]keyList) (keyBindings, []error) {
+	var errs []error
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if findAction(name) == nil {
+			if s := closest(name, actionNames()); s != "" {
+				errs = append(errs, fmt.Errorf("keys: unknown action %q (did you mean %q?)", name, s))
+			} else {
+				errs = append(errs, fmt.Errorf("keys: unknown action %q", name))
+			}
+		}
+	}
+
+	b := keyBindings{byKey: map[string]string{}, byAction: map[string][]string{}}
+	for _, a := range actions {
+		keys := a.keys
+		if k, ok := overrides[a.name]; ok {
+			keys = k
+		}
+		for _, k := range keys {
+			key := normalizeKey(k)
+			switch {
+			case key == "":
+				errs = append(errs, fmt.Errorf("keys.%s: %q is not a key", a.name, k))
+				continue
+			case key == "ctrl+c":
+				errs = append(errs, fmt.Errorf("keys.%s: ctrl+c always quits and can't be bound", a.name))
+				continue
+			}
+			if other, ok := b.byKey[key]; ok {
+				if other != a.name {
+					errs = append(errs, fmt.Errorf("keys: %q is bound to both %s and %s (rebind or unbind one)", keyLabel(key), other, a.name))
+				}
+				continue
+			}
+			b.byKey[key] = a.name
+			b.byAction[a.name] = append(b.byAction[a.name], key)
+		}
+	}
+	return b, errs
+}
+
+func actionNames() []string {
+	names := make([]string, len(actions))
+	for i, a := range actions {
+		names[i] = a.name
+	}
+	return names
+}
+
+// keyLabel is how a key is shown in help text.
+func keyLabel(key string) string {
+	switch key {
+	case " ":
+		return "space"
+	case "up":
+		return "↑"
+	case "down":
+		return "↓"
+	case "enter":
+		return "Enter"
+	}
+	return key
+}
+
+// keysLabel shows several keys: "j / ↓", or "1-9" for a run of
+// consecutive characters.
+func keysLabel(keys []string) string {
+	if len(keys) > 2 {
+		run := true
+		for i := 1; i < len(keys) && run; i++ {
+			prev, cur := []rune(keys[i-1]), []rune(keys[i])
+			run = len(prev) == 1 && len(cur) == 1 && cur[0] == prev[0]+1
+		}
+		if run {
+			return keys[0] + "-" + keys[len(keys)-1]
+		}
+	}
+	labels := make([]string, len(keys))
+	for i, k := range keys {
+		labels[i] = keyLabel(k)
+	}
+	return strings.Join(labels, " / ")
+}
+
+// footerHint is the key hint under the list, e.g. "?: help · q: quit".
+// Actions left without a key are skipped.
+func footerHint() string {
+	var parts []string
+	for _, a := range actions {
+		if keys := keysFor(a.name); a.footer != "" && len(keys) > 0 {
+			parts = append(parts, strings.ToLower(keyLabel(keys[0]))+": "+a.footer)
+		}
+	}
+	return strings.Join(parts, " · ")
+}
+
+// closeHint lists the keys that close an overlay opened by action name.
+func closeHint(name string) string {
+	keys := append([]string{}, keysFor(name)...)
+	keys = append(keys, "esc")
+	keys = append(keys, keysFor("quit")...)
+	return strings.Join(keys, " · ") + " to close"
+}
+
+// closesOverlay reports whether key closes an overlay opened by action
+// name: that action's keys, esc, or quit's keys.
+func closesOverlay(key, name string) bool {
+	a := actionFor(key)
+	return key == "esc" || a == name || a == "quit"
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// Exit codes for `sup list`, grep-style so scripts can branch on "nothing
+// matched" without parsing output.
+const (
+	exitOK      = 0
+	exitNoMatch = 1
+	exitError   = 2
+)
+
+// listEntry is the flattened, script-friendly shape `sup list` emits. It's
+// the JSON schema and the dot in --template.
+type listEntry struct {
+	Host      string    `json:"host"`
+	Repo      string    `json:"repo"` // owner/name
+	Number    int       `json:"number"`
+	Title     string    `json:"title"`
+	Author    string    `json:"author"`
+	Branch    string    `json:"branch"`
+	Status    string    `json:"status"` // draft, approved, denied, review, commented, open
+	CI        string    `json:"ci"`     // passing, failing, pending, or "" without checks
+	Draft     bool      `json:"draft"`
+	Additions int       `json:"additions"`
+	Deletions int       `json:"deletions"`
+	Reviewers []string  `json:"reviewers"`
+	Labels    []string  `json:"labels"`
+	CreatedAt time.Time `json:"createdAt"`
+	URL       string    `json:"url"`
+}
+
+func newListEntry(pr PR) listEntry {
+	reviewers := strings.Fields(getRequestedReviewerNames(pr))
+	if reviewers == nil {
+		reviewers = []string{}
+	}
+	labels := []string{}
+	for _, l := range pr.Labels.Nodes {
+		labels = append(labels, l.Name)
+	}
+	return listEntry{
+		Host:      prHost(pr),
+		Repo:      pr.Repository.Owner.Login + "/" + pr.Repository.Name,
+		Number:    pr.Number,
+		Title:     pr.Title,
+		Author:    pr.Author.Login,
+		Branch:    pr.HeadRefName,
+		Status:    statusLabelForFilter(pr),
+		CI:        ciState(pr),
+		Draft:     pr.IsDraft,
+		Additions: pr.Additions,
+		Deletions: pr.Deletions,
+		Reviewers: reviewers,
+		Labels:    labels,
+		CreatedAt: pr.CreatedAt,
+		URL:       prURL(pr),
+	}
+}
+
+func setupList(fs *flag.FlagSet) func([]string) int {
+	asJSON := fs.Bool("json", false, "print a JSON array")
+	asTSV := fs.Bool("tsv", false, "print tab-separated: repo, number, status, ci, author, branch, title, url")
+	tmpl := fs.String("template", "", "print each PR with a Go text/template (fields as in --json, e.g. '{{.Repo}}#{{.Number}}')")
+	cached := fs.Bool("cached", false, "read the TUI's PR cache instead of querying GitHub")
+	filter := fs.String("filter", "", "filter using the TUI's / syntax (also accepted as positional args)")
+	viewName := fs.String("view", "", "list a view from the config file: its search, filter and sort")
+	sortBy := fs.String("sort", "", "order by comma-separated keys, - for descending (e.g. -updated,repo)")
+	return func(args []string) int {
+		return runList(*asJSON, *asTSV, *tmpl, *cached, *filter, *viewName, *sortBy, args)
+	}
+}
+
+// runList prints PRs for scripts. Exit status is 0 when PRs matched, 1 when
+// none did, 2 on error.
+func runList(asJSON, asTSV bool, tmpl string, cached bool, filter, viewName, sortBy string, args []string) int {
+	query := strings.TrimSpace(strings.Join(append([]string{filter}, args...), " "))
+	view := 0
+	if viewName != "" {
+		if view = findView(viewName); view < 0 {
+			fmt.Fprintf(os.Stderr, "Error: no view named %q\n", viewName)
+			return exitError
+		}
+	}
+	if vf := views[view].Filter; vf != "" {
+		if query == "" {
+			query = vf
+		} else {
+			query = "(" + vf + ") (" + query + ")"
+		}
+	}
+
+	formats := 0
+	for _, set := range []bool{asJSON, asTSV, tmpl != ""} {
+		if set {
+			formats++
+		}
+	}
+	if formats > 1 {
+		fmt.Fprintln(os.Stderr, "Error: --json, --tsv and --template are mutually exclusive")
+		return exitError
+	}
+	expr, err := parseFilter(query)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: bad filter: %v\n", err)
+		return exitError
+	}
+	// Precedence: --sort, then a sort: filter term, then the view's sort.
+	order := views[view].sort
+	if spec := filterSort(expr); spec != nil {
+		order = spec
+	}
+	if sortBy != "" {
+		if order, err = parseSort(sortBy); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: bad --sort: %v\n", err)
+			return exitError
+		}
+	}
+
+	var prs []PR
+	if cached {
+		// No session without the network, but @me can still resolve from
+		// the cached login.
+		if meta, ok := loadCachedMeta(); ok {
+			currentUser = meta.CurrentUser
+		}
+		prs = loadViewCache(view)
+		if prs == nil {
+			fmt.Fprintln(os.Stderr, "Error: no cached PRs yet — run sup once, or drop --cached")
+			return exitError
+		}
+	} else {
+		if err := initSession(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitError
+		}
+		if prs, err = fetchAllPRs(viewShards(view)); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitError
+		}
+		if !demoMode {
+			saveViewCache(view, prs)
+		}
+	}
+
+	prs = filterPRs(prs, expr)
+	sortPRs(prs, order)
+
+	switch {
+	case asJSON:
+		err = writeListJSON(os.Stdout, prs)
+	case asTSV:
+		err = writeListTSV(os.Stdout, prs)
+	case tmpl != "":
+		err = writeListTemplate(os.Stdout, prs, tmpl)
+	default:
+		err = writeListPlain(os.Stdout, prs)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitError
+	}
+	if len(prs) == 0 {
+		return exitNoMatch
+	}
+	return exitOK
+}
+
+// fetchAllPRs runs every search shard to exhaustion, the same pagination the
+// TUI streams page by page, and returns the de-duplicated result.
+func fetchAllPRs(shards []searchShard) ([]PR, error) {
+	results := make([][]PR, len(shards))
+	errs := make([]error, len(shards))
+	var wg sync.WaitGroup
+	for i := range shards {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			after := ""
+			for {
+				msg := fetchShardPage(shards[i], i, after, 0)().(prPageLoadedMsg)
+				if msg.err != nil {
+					errs[i] = msg.err
+					return
+				}
+				results[i] = append(results[i], msg.prs...)
+				if !msg.hasNext {
+					return
+				}
+				after = msg.endCursor
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	seen := map[string]bool{}
+	var all []PR
+	for i := range shards {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		for _, pr := range results[i] {
+			if key := prKey(pr); !seen[key] {
+				seen[key] = true
+				all = append(all, pr)
+			}
+		}
+	}
+	return all, nil
+}
+
+func writeListJSON(w io.Writer, prs []PR) error {
+	entries := make([]listEntry, 0, len(prs))
+	for _, pr := range prs {
+		entries = append(entries, newListEntry(pr))
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
+
+// tsvField keeps a value on one line and in one column.
+func tsvField(s string) string {
+	return strings.NewReplacer("\t", " ", "\n", " ", "\r", "").Replace(s)
+}
+
+func writeListTSV(w io.Writer, prs []PR) error {
+	for _, pr := range prs {
+		e := newListEntry(pr)
+		_, err := fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			e.Repo, e.Number, e.Status, e.CI, e.Author, tsvField(e.Branch), tsvField(e.Title), e.URL)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeListTemplate(w io.Writer, prs []PR, text string) error {
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	t, err := template.New("list").Funcs(template.FuncMap{
+		"join":  strings.Join,
+		"lower": strings.ToLower,
+		"upper": strings.ToUpper,
+	}).Parse(text)
+	if err != nil {
+		return fmt.Errorf("bad --template: %w", err)
+	}
+	for _, pr := range prs {
+		if err := t.Execute(w, newListEntry(pr)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeListPlain(w io.Writer, prs []PR) error {
+	for _, pr := range prs {
+		e := newListEntry(pr)
+		if _, err := fmt.Fprintf(w, "%-10s %s#%d  %s (%s)\n", e.Status, e.Repo, e.Number, e.Title, e.Author); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -4,12 +4,10 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"sort"
 	"strings"
 	"time"
 
@@ -21,8 +19,8 @@ var (
 	orgs        []string // Auto-detected or from SUP_ORG
 	mineMode    bool     // Show PRs involving current user
 	demoMode    bool     // Show mock data for screenshots
+	allowDirty  bool     // --allow-dirty, the older spelling of --on-dirty carry
 	currentUser string   // Authenticated GitHub username
-	ghToken     string   // Cached `gh auth token` for direct HTTP calls
 
 	httpClient = &http.Client{
 		Timeout: 30 * time.Second,
@@ -34,80 +32,6 @@ var (
 	}
 )
 
-func tokenCachePath() string { return filepath.Join(cacheDir(), "token") }
-
-func loadGHToken() error {
-	if data, err := os.ReadFile(tokenCachePath()); err == nil {
-		if t := strings.TrimSpace(string(data)); t != "" {
-			ghToken = t
-			return nil
-		}
-	}
-	return refreshGHToken()
-}
-
-func refreshGHToken() error {
-	out, err := exec.Command("gh", "auth", "token").Output()
-	if err != nil {
-		return err
-	}
-	t := strings.TrimSpace(string(out))
-	if t == "" {
-		return fmt.Errorf("empty token from gh auth token")
-	}
-	ghToken = t
-	_ = os.WriteFile(tokenCachePath(), []byte(t), 0600)
-	return nil
-}
-
-func invalidateGHToken() {
-	_ = os.Remove(tokenCachePath())
-	ghToken = ""
-}
-
-func graphqlPOST(query string) ([]byte, error) {
-	data, status, err := graphqlPOSTOnce(query)
-	if err != nil {
-		return nil, err
-	}
-	// Token may have rotated since we cached it — refresh once and retry.
-	if status == 401 {
-		invalidateGHToken()
-		if err := refreshGHToken(); err != nil {
-			return nil, fmt.Errorf("auth refresh failed: %w", err)
-		}
-		data, status, err = graphqlPOSTOnce(query)
-		if err != nil {
-			return nil, err
-		}
-	}
-	if status >= 400 {
-		return nil, fmt.Errorf("graphql %d: %s", status, strings.TrimSpace(string(data)))
-	}
-	return data, nil
-}
-
-func graphqlPOSTOnce(query string) ([]byte, int, error) {
-	body, _ := json.Marshal(map[string]string{"query": query})
-	req, err := http.NewRequest("POST", "https://api.github.com/graphql", bytes.NewReader(body))
-	if err != nil {
-		return nil, 0, err
-	}
-	req.Header.Set("Authorization", "Bearer "+ghToken)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
-	resp, err := httpClient.Do(req)
-	if err != nil {
-		return nil, 0, err
-	}
-	defer resp.Body.Close()
-	data, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, resp.StatusCode, err
-	}
-	return data, resp.StatusCode, nil
-}
-
 // Output file for shell integration (shell wrapper reads this to cd)
 const selectionFile = "/tmp/sup-selection"
 
@@ -161,43 +85,76 @@ func saveCachedMeta(m metaCache) {
 	os.WriteFile(getMetaCachePath(), data, 0644)
 }
 
-func getCacheFilePath() string {
+// getCacheFilePath returns the PR cache for a host. github.com keeps the
+// original prs.json name so existing caches stay warm.
+func getCacheFilePath(host string) string {
 	cacheDir := os.Getenv("XDG_CACHE_HOME")
 	if cacheDir == "" {
 		cacheDir = os.Getenv("HOME") + "/.cache"
 	}
 	cacheDir = filepath.Join(cacheDir, "sup")
 	os.MkdirAll(cacheDir, 0755)
-	return filepath.Join(cacheDir, "prs.json")
+	if host == githubDotCom {
+		return filepath.Join(cacheDir, "prs.json")
+	}
+	return filepath.Join(cacheDir, "prs-"+host+".json")
 }
 
 func loadCachedPRs() []PR {
-	data, err := os.ReadFile(getCacheFilePath())
-	if err != nil {
-		return nil
+	var all []PR
+	found := false
+	for _, host := range knownHosts() {
+		data, err := os.ReadFile(getCacheFilePath(host))
+		if err != nil {
+			continue
+		}
+		var prs []PR
+		if err := json.Unmarshal(data, &prs); err != nil {
+			continue
+		}
+		found = true
+		for _, pr := range prs {
+			// Only trust entries that belong to this file's host.
+			if prHost(pr) == host {
+				all = append(all, pr)
+			}
+		}
 	}
-	var prs []PR
-	if err := json.Unmarshal(data, &prs); err != nil {
+	if !found {
 		return nil
 	}
-	return prs
+	return all
 }
 
 func savePRsToCache(prs []PR) {
-	data, err := json.Marshal(prs)
-	if err != nil {
-		return
+	byHost := map[string][]PR{}
+	for _, host := range knownHosts() {
+		byHost[host] = []PR{}
+	}
+	for _, pr := range prs {
+		byHost[prHost(pr)] = append(byHost[prHost(pr)], pr)
+	}
+	for host, hostPRs := range byHost {
+		data, err := json.Marshal(hostPRs)
+		if err != nil {
+			continue
+		}
+		os.WriteFile(getCacheFilePath(host), data, 0644)
 	}
-	os.WriteFile(getCacheFilePath(), data, 0644)
 }
 
 type PR struct {
-	Number      int    `json:"number"`
-	Title       string `json:"title"`
-	HeadRefName string `json:"headRefName"`
-	IsDraft     bool   `json:"isDraft"`
-	Additions   int    `json:"additions"`
-	Deletions   int    `json:"deletions"`
+	Host        string    `json:"host,omitempty"` // set by the backend; "" means github.com
+	Number      int       `json:"number"`
+	Title       string    `json:"title"`
+	HeadRefName string    `json:"headRefName"`
+	BaseRefName string    `json:"baseRefName"`
+	State       string    `json:"state"` // OPEN, CLOSED or MERGED
+	IsDraft     bool      `json:"isDraft"`
+	Additions   int       `json:"additions"`
+	Deletions   int       `json:"deletions"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
 	Author      struct {
 		Login string `json:"login"`
 	} `json:"author"`
@@ -207,6 +164,23 @@ type PR struct {
 			Login string `json:"login"`
 		} `json:"owner"`
 	} `json:"repository"`
+	// HeadRefOid and HeadRepository are only fetched by BranchPRs, to tell
+	// a local branch that is the PR's from one that shares its name.
+	HeadRefOid     string `json:"headRefOid,omitempty"`
+	HeadRepository *struct {
+		Name  string `json:"name"`
+		Owner struct {
+			Login string `json:"login"`
+		} `json:"owner"`
+	} `json:"headRepository,omitempty"` // nil once a fork is deleted
+	Labels struct {
+		Nodes []struct {
+			Name string `json:"name"`
+		} `json:"nodes"`
+	} `json:"labels"`
+	Comments struct {
+		TotalCount int `json:"totalCount"`
+	} `json:"comments"`
 	ReviewDecision string `json:"reviewDecision"`
 	ReviewRequests struct {
 		TotalCount int `json:"totalCount"`
@@ -225,37 +199,65 @@ type PR struct {
 			State string `json:"state"`
 		} `json:"nodes"`
 	} `json:"reviews"`
+	Commits struct {
+		Nodes []struct {
+			Commit struct {
+				StatusCheckRollup *checkRollup `json:"statusCheckRollup"`
+			} `json:"commit"`
+		} `json:"nodes"`
+	} `json:"commits"`
 }
 
 var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
 
 type model struct {
-	prs          []PR
-	filtered     []PR
-	cursor       int
-	selected     *PR
-	filterMode   bool
-	filterText   string
-	err          error
-	quitting     bool
-	width        int
-	height       int
-	loading      bool
-	refreshing   bool // true when fetching new data while showing cached data
-	loadingDiff   bool // true while fetching diff before launching hunk
-	diffError     string
-	confirmAction string // non-empty while awaiting y/n confirmation (e.g. "approve")
-	confirmPR     *PR
-	actionPending bool   // true while a review submission is in flight
-	actionStatus  string // transient success/error feedback for review actions
-	helpMode      bool   // true while the help overlay is showing
-	visibleCount  int    // for animation
-	spinnerFrame  int    // for loading spinner
-	refreshSeen   map[string]bool // PR keys seen during the in-flight refresh
-	refreshID     int             // increments each refresh; stale page messages are dropped
-	pendingShards int             // shards still streaming pages for the current refresh
-	statusFilterIndex int          // current index in statusFilters array (-1 means no filter)
-	authorFilter  string          // author filter (e.g., "!username"), empty means no author filter
+	prs            []PR
+	filtered       []PR
+	cursor         int
+	selected       *PR
+	inWorktree     bool   // check selected out in a new worktree
+	cloneThen      string // set while confirmAction == "clone": the action to continue with
+	filterMode     bool
+	filterText     string
+	err            error
+	quitting       bool
+	width          int
+	height         int
+	loading        bool
+	refreshing     bool // true when fetching new data while showing cached data
+	loadingDiff    bool // true while fetching diff before launching hunk
+	diffError      string
+	confirmAction  string // non-empty while a prompt is showing: "approve", "merge", "copy"
+	confirmPR      *PR
+	mergeInfo      *mergeInfo          // set while confirmAction == "merge"
+	mergeAuto      bool                // merge prompt: enable auto-merge instead of merging now
+	mergeDelete    bool                // merge prompt: delete the head branch afterwards
+	actionPending  bool                // true while a review submission is in flight
+	actionStatus   string              // transient success/error feedback for review actions
+	pendingLabel   string              // spinner text while actionPending; "" means a review
+	helpMode       bool                // true while the help overlay is showing
+	checksPR       *PR                 // non-nil while the failing-checks overlay is showing
+	worktrees      *worktreesScreen    // non-nil while the worktrees screen is showing
+	detailOpen     bool                // detail pane toggled on
+	detailKey      string              // prKey of the PR the pane last showed
+	detailScroll   int                 // lines scrolled within the pane
+	details        map[string]PRDetail // lazily loaded, keyed by prKey
+	detailLoading  map[string]bool
+	detailErrs     map[string]error
+	visibleCount   int             // for animation
+	spinnerFrame   int             // for loading spinner
+	refreshSeen    map[string]bool // PR keys seen during the in-flight refresh
+	failedHosts    map[string]bool // hosts with a shard that failed this refresh; their unseen PRs stay
+	refreshID      int             // increments each refresh; stale page messages are dropped
+	pendingShards  int             // shards still streaming pages for the current refresh
+	filter         filterExpr      // last query that parsed; nil matches everything
+	filterErr      error           // why filterText doesn't parse, shown inline
+	view           int             // index into views
+	viewStates     []viewState     // per view, for switching back
+	sort           sortSpec        // the view's order; a sort: filter term overrides it
+	shards         []searchShard   // searches behind the in-flight refresh
+	refreshErrs    int             // fetches that failed during the current refresh
+	lastRefreshErr error           // most recent of those, shown in the footer
 }
 
 type prPageLoadedMsg struct {
@@ -268,6 +270,9 @@ type prPageLoadedMsg struct {
 }
 
 func prKey(pr PR) string {
+	if h := prHost(pr); h != githubDotCom {
+		return fmt.Sprintf("%s/%s/%s#%d", h, pr.Repository.Owner.Login, pr.Repository.Name, pr.Number)
+	}
 	return fmt.Sprintf("%s/%s#%d", pr.Repository.Owner.Login, pr.Repository.Name, pr.Number)
 }
 
@@ -294,8 +299,11 @@ type reviewSubmittedMsg struct {
 	err    error
 }
 
+// prRefreshedMsg carries fresh copies of already-listed PRs: one for a
+// single-PR refresh, up to refreshBatchSize for a batched one. err may be set
+// alongside prs when only part of a batch came back.
 type prRefreshedMsg struct {
-	pr  PR
+	prs []PR
 	err error
 }
 
@@ -319,295 +327,116 @@ func spinnerTick() tea.Cmd {
 	})
 }
 
-var (
-	titleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("205"))
-
-	selectedStyle = lipgloss.NewStyle().
-			Bold(true)
-
-	caretStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("33"))
-
-	normalStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("252"))
-
-	selectedNormalStyle = lipgloss.NewStyle().
-				Bold(true).
-				Foreground(lipgloss.Color("15"))
-
-	draftStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241"))
-
-	selectedDraftStyle = lipgloss.NewStyle().
-				Bold(true).
-				Foreground(lipgloss.Color("250"))
-
-	approvedStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("78"))
-
-	selectedApprovedStyle = lipgloss.NewStyle().
-				Bold(true).
-				Foreground(lipgloss.Color("114"))
-
-	changesRequestedStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("196"))
-
-	selectedChangesRequestedStyle = lipgloss.NewStyle().
-					Bold(true).
-					Foreground(lipgloss.Color("203"))
-
-	reviewRequestedStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("214"))
-
-	selectedReviewRequestedStyle = lipgloss.NewStyle().
-					Bold(true).
-					Foreground(lipgloss.Color("221"))
-
-	commentedStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("117"))
-
-	selectedCommentedStyle = lipgloss.NewStyle().
-				Bold(true).
-				Foreground(lipgloss.Color("159"))
-
-	openStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("39"))
-
-	selectedOpenStyle = lipgloss.NewStyle().
-				Bold(true).
-				Foreground(lipgloss.Color("81"))
-
-	additionsStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("78"))
-
-	selectedAdditionsStyle = lipgloss.NewStyle().
-				Bold(true).
-				Foreground(lipgloss.Color("114"))
-
-	deletionsStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("196"))
-
-	selectedDeletionsStyle = lipgloss.NewStyle().
-				Bold(true).
-				Foreground(lipgloss.Color("203"))
-
-	filterStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("220")).
-			Bold(true)
-
-	helpStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241"))
-
-	branchStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("141"))
-
-	selectedBranchStyle = lipgloss.NewStyle().
-				Bold(true).
-				Foreground(lipgloss.Color("183"))
-)
-
-func sortPRsByOldestFirst(prs []PR) {
-	sort.Slice(prs, func(i, j int) bool {
-		return prs[i].Number < prs[j].Number
-	})
-}
-
 func initialModel() model {
-	// Skip cache in demo mode
-	if !demoMode {
-		cached := loadCachedPRs()
-		if cached != nil {
-			sortPRsByOldestFirst(cached)
-			return model{
-				prs:               cached,
-				filtered:          cached,
-				cursor:            0,
-				loading:           false,
-				refreshing:        true,
-				visibleCount:      len(cached),
-				statusFilterIndex: -1,
-				authorFilter:      "",
-			}
-		}
-	}
-	return model{
-		prs:               []PR{},
-		filtered:          []PR{},
-		cursor:            0,
-		loading:           true,
-		visibleCount:      0,
-		statusFilterIndex: -1,
-		authorFilter:      "",
-	}
+	m := model{
+		details:       map[string]PRDetail{},
+		detailLoading: map[string]bool{},
+		detailErrs:    map[string]error{},
+		viewStates:    make([]viewState, len(views)),
+	}
+	// Init starts the first refresh; with cached rows showing, that's a
+	// background refresh rather than a load.
+	m.enterView(max(startView(), 0))
+	m.refreshing = !m.loading
+	return m
 }
 
 func mockPRs() []PR {
 	mockJSON := `[
-		{"number": 142, "title": "Add user authentication flow", "headRefName": "feature/auth-flow", "isDraft": false, "additions": 847, "deletions": 123, "author": {"login": "sarah"}, "repository": {"name": "backend-api", "owner": {"login": "acme-corp"}}, "reviewDecision": "APPROVED", "reviews": {"nodes": [{"author": {"login": "mike"}, "state": "APPROVED"}]}},
-		{"number": 287, "title": "Fix memory leak in worker pool", "headRefName": "fix/worker-memory", "isDraft": false, "additions": 34, "deletions": 89, "author": {"login": "alex"}, "repository": {"name": "job-runner", "owner": {"login": "acme-corp"}}, "reviewDecision": "CHANGES_REQUESTED", "reviews": {"nodes": [{"author": {"login": "sarah"}, "state": "CHANGES_REQUESTED"}]}},
-		{"number": 91, "title": "Update dashboard metrics components", "headRefName": "feature/metrics-v2", "isDraft": false, "additions": 456, "deletions": 201, "author": {"login": "mike"}, "repository": {"name": "web-app", "owner": {"login": "acme-corp"}}, "reviewDecision": "REVIEW_REQUIRED", "reviewRequests": {"totalCount": 1, "nodes": [{"requestedReviewer": {"login": "alex"}}]}},
-		{"number": 445, "title": "Implement rate limiting middleware", "headRefName": "feature/rate-limit", "isDraft": true, "additions": 234, "deletions": 12, "author": {"login": "jordan"}, "repository": {"name": "backend-api", "owner": {"login": "acme-corp"}}},
-		{"number": 156, "title": "Add PostgreSQL connection pooling", "headRefName": "feature/pg-pool", "isDraft": false, "additions": 178, "deletions": 45, "author": {"login": "chris"}, "repository": {"name": "data-service", "owner": {"login": "acme-corp"}}, "reviewDecision": "REVIEW_REQUIRED", "reviewRequests": {"totalCount": 1, "nodes": [{"requestedReviewer": {"login": "jordan"}}]}, "reviews": {"nodes": [{"author": {"login": "alex"}, "state": "COMMENTED"}]}},
-		{"number": 312, "title": "Refactor notification service", "headRefName": "refactor/notifications", "isDraft": false, "additions": 623, "deletions": 891, "author": {"login": "taylor"}, "repository": {"name": "backend-api", "owner": {"login": "acme-corp"}}, "reviewDecision": "APPROVED", "reviews": {"nodes": [{"author": {"login": "chris"}, "state": "APPROVED"}]}},
-		{"number": 78, "title": "Add dark mode support", "headRefName": "feature/dark-mode", "isDraft": false, "additions": 567, "deletions": 234, "author": {"login": "sam"}, "repository": {"name": "web-app", "owner": {"login": "acme-corp"}}, "reviewDecision": "REVIEW_REQUIRED", "reviewRequests": {"totalCount": 1, "nodes": [{"requestedReviewer": {"login": "taylor"}}]}},
-		{"number": 203, "title": "Upgrade to Go 1.22", "headRefName": "chore/go-upgrade", "isDraft": true, "additions": 23, "deletions": 19, "author": {"login": "alex"}, "repository": {"name": "cli-tools", "owner": {"login": "acme-corp"}}}
+		{"number": 142, "title": "Add user authentication flow", "headRefName": "feature/auth-flow", "isDraft": false, "additions": 847, "deletions": 123, "labels": {"nodes": [{"name": "security"}]}, "author": {"login": "sarah"}, "repository": {"name": "backend-api", "owner": {"login": "acme-corp"}}, "reviewDecision": "APPROVED", "reviews": {"nodes": [{"author": {"login": "mike"}, "state": "APPROVED"}]}, "commits": {"nodes": [{"commit": {"statusCheckRollup": {"state": "SUCCESS"}}}]}},
+		{"number": 287, "title": "Fix memory leak in worker pool", "headRefName": "fix/worker-memory", "isDraft": false, "additions": 34, "deletions": 89, "labels": {"nodes": [{"name": "bug"}]}, "author": {"login": "alex"}, "repository": {"name": "job-runner", "owner": {"login": "acme-corp"}}, "reviewDecision": "CHANGES_REQUESTED", "reviews": {"nodes": [{"author": {"login": "sarah"}, "state": "CHANGES_REQUESTED"}]}, "commits": {"nodes": [{"commit": {"statusCheckRollup": {"state": "FAILURE", "contexts": {"nodes": [{"__typename": "CheckRun", "name": "test (ubuntu-latest)", "status": "COMPLETED", "conclusion": "FAILURE", "detailsUrl": "https://github.com/acme-corp/job-runner/actions/runs/1"}, {"__typename": "CheckRun", "name": "lint", "status": "COMPLETED", "conclusion": "SUCCESS"}]}}}}]}},
+		{"number": 91, "title": "Update dashboard metrics components", "headRefName": "feature/metrics-v2", "isDraft": false, "additions": 456, "deletions": 201, "author": {"login": "mike"}, "repository": {"name": "web-app", "owner": {"login": "acme-corp"}}, "reviewDecision": "REVIEW_REQUIRED", "reviewRequests": {"totalCount": 1, "nodes": [{"requestedReviewer": {"login": "alex"}}]}, "commits": {"nodes": [{"commit": {"statusCheckRollup": {"state": "PENDING"}}}]}},
+		{"number": 445, "title": "Implement rate limiting middleware", "headRefName": "feature/rate-limit", "isDraft": true, "additions": 234, "deletions": 12, "labels": {"nodes": [{"name": "security"}, {"name": "needs-design"}]}, "author": {"login": "jordan"}, "repository": {"name": "backend-api", "owner": {"login": "acme-corp"}}},
+		{"number": 156, "title": "Add PostgreSQL connection pooling", "headRefName": "feature/pg-pool", "isDraft": false, "additions": 178, "deletions": 45, "author": {"login": "chris"}, "repository": {"name": "data-service", "owner": {"login": "acme-corp"}}, "reviewDecision": "REVIEW_REQUIRED", "reviewRequests": {"totalCount": 1, "nodes": [{"requestedReviewer": {"login": "jordan"}}]}, "reviews": {"nodes": [{"author": {"login": "alex"}, "state": "COMMENTED"}]}, "commits": {"nodes": [{"commit": {"statusCheckRollup": {"state": "SUCCESS"}}}]}},
+		{"number": 312, "title": "Refactor notification service", "headRefName": "refactor/notifications", "isDraft": false, "additions": 623, "deletions": 891, "labels": {"nodes": [{"name": "tech-debt"}]}, "author": {"login": "taylor"}, "repository": {"name": "backend-api", "owner": {"login": "acme-corp"}}, "reviewDecision": "APPROVED", "reviews": {"nodes": [{"author": {"login": "chris"}, "state": "APPROVED"}]}, "commits": {"nodes": [{"commit": {"statusCheckRollup": {"state": "SUCCESS"}}}]}},
+		{"number": 78, "title": "Add dark mode support", "headRefName": "feature/dark-mode", "isDraft": false, "additions": 567, "deletions": 234, "labels": {"nodes": [{"name": "ui"}]}, "author": {"login": "sam"}, "repository": {"name": "web-app", "owner": {"login": "acme-corp"}}, "reviewDecision": "REVIEW_REQUIRED", "reviewRequests": {"totalCount": 1, "nodes": [{"requestedReviewer": {"login": "taylor"}}]}, "commits": {"nodes": [{"commit": {"statusCheckRollup": {"state": "FAILURE", "contexts": {"nodes": [{"__typename": "CheckRun", "name": "test (ubuntu-latest)", "status": "COMPLETED", "conclusion": "FAILURE", "detailsUrl": "https://github.com/acme-corp/web-app/actions/runs/1"}, {"__typename": "CheckRun", "name": "lint", "status": "COMPLETED", "conclusion": "SUCCESS"}]}}}}]}},
+		{"number": 203, "title": "Upgrade to Go 1.22", "headRefName": "chore/go-upgrade", "isDraft": true, "additions": 23, "deletions": 19, "labels": {"nodes": [{"name": "dependencies"}]}, "author": {"login": "alex"}, "repository": {"name": "cli-tools", "owner": {"login": "acme-corp"}}}
 	]`
 	var prs []PR
 	json.Unmarshal([]byte(mockJSON), &prs)
+	// Ages are relative so age: filters and time sorts behave the same on
+	// every run.
+	ageDays := map[int]int{142: 2, 287: 9, 91: 1, 445: 21, 156: 4, 312: 14, 78: 6, 203: 30}
+	idleHours := map[int]int{142: 3, 287: 50, 91: 1, 445: 200, 156: 20, 312: 8, 78: 30, 203: 400}
+	comments := map[int]int{142: 4, 287: 11, 91: 2, 156: 6, 312: 3, 78: 1}
+	for i := range prs {
+		prs[i].CreatedAt = time.Now().Add(-time.Duration(ageDays[prs[i].Number]) * 24 * time.Hour)
+		prs[i].UpdatedAt = time.Now().Add(-time.Duration(idleHours[prs[i].Number]) * time.Hour)
+		prs[i].Comments.TotalCount = comments[prs[i].Number]
+		prs[i].BaseRefName = "main"
+	}
 	return prs
 }
 
 // findWorktreePath returns the path of a worktree checked out on the given branch, or "".
 // repoPath is the main worktree root; branch is the short branch name.
 func findWorktreePath(repoPath, branch string) string {
-	cmd := exec.Command("git", "worktree", "list", "--porcelain")
-	cmd.Dir = repoPath
-	out, err := cmd.Output()
-	if err != nil {
-		return ""
-	}
-	var currentPath string
-	for _, line := range strings.Split(string(out), "\n") {
-		if strings.HasPrefix(line, "worktree ") {
-			currentPath = strings.TrimPrefix(line, "worktree ")
-		} else if line == "branch refs/heads/"+branch && currentPath != "" {
-			return currentPath
+	wts, _ := listWorktrees(repoPath)
+	for _, wt := range wts {
+		if wt.Branch == branch {
+			return wt.Path
 		}
 	}
 	return ""
 }
 
-// findRepoPath searches for a repo in common locations
-func findRepoPath(repoName string) string {
-	home := os.Getenv("HOME")
-
-	// Check SUP_DEV_DIR first if set
-	if devDir := os.Getenv("SUP_DEV_DIR"); devDir != "" {
-		path := filepath.Join(devDir, repoName)
-		if _, err := os.Stat(filepath.Join(path, ".git")); err == nil {
-			return path
-		}
+// repoRoots are the directories the repo index walks: repo_roots from the
+// config, or the common locations.
+func repoRoots() []string {
+	roots := cfg.RepoRoots
+	if len(roots) == 0 {
+		roots = defaultDevDirs
 	}
-
-	// Search common locations
-	for _, dir := range defaultDevDirs {
-		var path string
-		if dir == "" {
-			path = filepath.Join(home, repoName)
-		} else {
-			path = filepath.Join(home, dir, repoName)
-		}
-		if _, err := os.Stat(filepath.Join(path, ".git")); err == nil {
-			return path
-		}
+	out := make([]string, len(roots))
+	for i, dir := range roots {
+		out[i] = expandPath(dir)
 	}
-
-	return ""
+	return out
 }
 
-// getCurrentUser gets the authenticated GitHub username
-func getCurrentUser() string {
-	cmd := exec.Command("gh", "api", "user", "--jq", ".login")
-	output, err := cmd.Output()
-	if err != nil {
-		return ""
+// findRepoPath finds the local clone of owner/name on host: a [repos]
+// mapping from the config, whatever the host, then the repo index
+// (SUP_DEV_DIR and the repo roots).
+func findRepoPath(host, owner, name string) string {
+	for repo, path := range cfg.Repos {
+		if strings.EqualFold(repo, owner+"/"+name) {
+			return expandPath(path)
+		}
 	}
-	return strings.TrimSpace(string(output))
+	return lookupRepo(host, owner, name)
 }
 
-// fetchUserOrgs gets the list of organizations the user belongs to
-func fetchUserOrgs() ([]string, error) {
-	cmd := exec.Command("gh", "api", "user/orgs", "--jq", ".[].login")
-	output, err := cmd.Output()
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch orgs: %w", err)
-	}
-
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	var result []string
-	for _, line := range lines {
-		if line = strings.TrimSpace(line); line != "" {
-			result = append(result, line)
-		}
-	}
-	return result, nil
+// searchShard is one GitHub search query run against one host.
+type searchShard struct {
+	Host  string
+	Query string
 }
 
-func searchShards() []string {
+func searchShards() []searchShard {
+	var s []searchShard
 	if mineMode {
-		return []string{"involves:@me is:pr is:open"}
+		for _, h := range knownHosts() {
+			s = append(s, searchShard{Host: h, Query: "involves:@me is:pr is:open"})
+		}
+		return s
 	}
-	var s []string
 	for _, o := range orgs {
-		s = append(s, "org:"+o+" is:pr is:open")
+		s = append(s, searchShard{Host: hostForOrg(o), Query: "org:" + o + " is:pr is:open"})
 	}
 	return s
 }
 
-func fetchShardPage(shardIdx int, after string, refreshID int) tea.Cmd {
+func fetchShardPage(shard searchShard, shardIdx int, after string, refreshID int) tea.Cmd {
 	return func() tea.Msg {
-		if demoMode {
-			return prPageLoadedMsg{prs: mockPRs(), shardIdx: shardIdx, refreshID: refreshID}
-		}
-
-		shards := searchShards()
-		if shardIdx >= len(shards) {
-			return prPageLoadedMsg{shardIdx: shardIdx, refreshID: refreshID}
-		}
-
-		afterArg := "null"
-		if after != "" {
-			afterArg = `"` + after + `"`
-		}
-
-		query := fmt.Sprintf(`{
-			search(query: "%s", type: ISSUE, first: 50, after: %s) {
-				pageInfo { endCursor hasNextPage }
-				nodes {
-					... on PullRequest {
-						number
-						title
-						headRefName
-						isDraft
-						additions
-						deletions
-						author { login }
-						repository { name owner { login } }
-						reviewDecision
-						reviewRequests(first: 5) { totalCount nodes { requestedReviewer { ... on User { login } ... on Team { name } } } }
-						reviews(last: 5) { nodes { author { login } state } }
-					}
-				}
-			}
-		}`, shards[shardIdx], afterArg)
-
-		output, err := graphqlPOST(query)
+		page, err := backendFor(shard.Host).SearchPRs(shard.Query, after)
 		if err != nil {
 			return prPageLoadedMsg{shardIdx: shardIdx, refreshID: refreshID, err: fmt.Errorf("failed to fetch PRs: %w", err)}
 		}
 
-		var resp struct {
-			Data struct {
-				Search struct {
-					PageInfo struct {
-						EndCursor   string `json:"endCursor"`
-						HasNextPage bool   `json:"hasNextPage"`
-					} `json:"pageInfo"`
-					Nodes []PR `json:"nodes"`
-				} `json:"search"`
-			} `json:"data"`
-		}
-		if err := json.Unmarshal(output, &resp); err != nil {
-			return prPageLoadedMsg{shardIdx: shardIdx, refreshID: refreshID, err: fmt.Errorf("failed to parse PRs: %w", err)}
-		}
-
 		return prPageLoadedMsg{
-			prs:       resp.Data.Search.Nodes,
-			endCursor: resp.Data.Search.PageInfo.EndCursor,
-			hasNext:   resp.Data.Search.PageInfo.HasNextPage,
+			prs:       page.PRs,
+			endCursor: page.EndCursor,
+			hasNext:   page.HasNext,
 			shardIdx:  shardIdx,
 			refreshID: refreshID,
 		}
@@ -620,23 +449,41 @@ func fetchShardPage(shardIdx int, after string, refreshID int) tea.Cmd {
 func (m *model) startRefresh() tea.Cmd {
 	m.refreshID++
 	m.refreshSeen = make(map[string]bool)
-	shards := searchShards()
+	m.failedHosts = make(map[string]bool)
+	m.refreshErrs = 0
+	m.lastRefreshErr = nil
+	shards := viewShards(m.view)
+	m.shards = shards
 	if len(shards) == 0 {
 		m.refreshing = false
 		return nil
 	}
 	m.pendingShards = len(shards)
 	cmds := make([]tea.Cmd, 0, len(shards)+4)
-	for i := range shards {
-		cmds = append(cmds, fetchShardPage(i, "", m.refreshID))
-	}
-
-	// Fast path: fire one request per cached PR. tea.Batch runs them as
-	// concurrent goroutines, so each row updates the moment its response
-	// arrives (HTTP/2 multiplexes them over a single connection).
-	if !demoMode {
-		for _, p := range m.prs {
-			cmds = append(cmds, fetchSinglePRCmd(p))
+	for i, shard := range shards {
+		cmds = append(cmds, fetchShardPage(shard, i, "", m.refreshID))
+	}
+
+	// Fast path: refresh cached PRs in aliased batches, one request per chunk
+	// per host. tea.Batch runs the chunks concurrently, so rows update as soon
+	// as their chunk lands rather than waiting on the (slower) search shards.
+	byHost := map[string][]PR{}
+	var hosts []string
+	for _, p := range m.prs {
+		h := prHost(p)
+		if _, ok := byHost[h]; !ok {
+			hosts = append(hosts, h)
+		}
+		byHost[h] = append(byHost[h], p)
+	}
+	for _, h := range hosts {
+		prs := byHost[h]
+		for start := 0; start < len(prs); start += refreshBatchSize {
+			end := start + refreshBatchSize
+			if end > len(prs) {
+				end = len(prs)
+			}
+			cmds = append(cmds, fetchPRBatchCmd(h, prs[start:end]))
 		}
 	}
 
@@ -646,17 +493,9 @@ func (m *model) startRefresh() tea.Cmd {
 
 func fetchDiffCmd(pr PR, mode string) tea.Cmd {
 	return func() tea.Msg {
-		repoSlug := fmt.Sprintf("%s/%s", pr.Repository.Owner.Login, pr.Repository.Name)
-		cmd := exec.Command("gh", "pr", "diff", fmt.Sprintf("%d", pr.Number), "--repo", repoSlug)
-		var stderr bytes.Buffer
-		cmd.Stderr = &stderr
-		out, err := cmd.Output()
+		out, err := backendFor(prHost(pr)).FetchDiff(pr)
 		if err != nil {
-			msg := strings.TrimSpace(stderr.String())
-			if msg == "" {
-				msg = err.Error()
-			}
-			return diffFetchedMsg{mode: mode, err: fmt.Errorf("%s", msg)}
+			return diffFetchedMsg{mode: mode, err: err}
 		}
 		return diffFetchedMsg{patch: out, mode: mode}
 	}
@@ -664,25 +503,8 @@ func fetchDiffCmd(pr PR, mode string) tea.Cmd {
 
 func submitReviewCmd(action string, pr PR, body string) tea.Cmd {
 	return func() tea.Msg {
-		repoSlug := fmt.Sprintf("%s/%s", pr.Repository.Owner.Login, pr.Repository.Name)
-		args := []string{"pr", "review", fmt.Sprintf("%d", pr.Number), "--repo", repoSlug}
-		switch action {
-		case "approve":
-			args = append(args, "--approve")
-		case "request-changes":
-			args = append(args, "--request-changes", "--body", body)
-		case "comment":
-			args = append(args, "--comment", "--body", body)
-		}
-		cmd := exec.Command("gh", args...)
-		var stderr bytes.Buffer
-		cmd.Stderr = &stderr
-		if err := cmd.Run(); err != nil {
-			msg := strings.TrimSpace(stderr.String())
-			if msg == "" {
-				msg = err.Error()
-			}
-			return reviewSubmittedMsg{action: action, pr: pr, err: fmt.Errorf("%s", msg)}
+		if err := backendFor(prHost(pr)).SubmitReview(pr, action, body); err != nil {
+			return reviewSubmittedMsg{action: action, pr: pr, err: err}
 		}
 		return reviewSubmittedMsg{action: action, pr: pr}
 	}
@@ -690,43 +512,48 @@ func submitReviewCmd(action string, pr PR, body string) tea.Cmd {
 
 func fetchSinglePRCmd(pr PR) tea.Cmd {
 	return func() tea.Msg {
-		query := fmt.Sprintf(`{
-			repository(owner: "%s", name: "%s") {
-				pullRequest(number: %d) {
-					number
-					title
-					headRefName
-					isDraft
-					additions
-					deletions
-					author { login }
-					reviewDecision
-					reviewRequests(first: 5) { totalCount nodes { requestedReviewer { ... on User { login } ... on Team { name } } } }
-					reviews(last: 5) { nodes { author { login } state } }
-				}
-			}
-		}`, pr.Repository.Owner.Login, pr.Repository.Name, pr.Number)
-
-		out, err := graphqlPOST(query)
+		updated, err := backendFor(prHost(pr)).FetchPR(pr.Repository.Owner.Login, pr.Repository.Name, pr.Number)
 		if err != nil {
 			return prRefreshedMsg{err: err}
 		}
-		var resp struct {
-			Data struct {
-				Repository struct {
-					PullRequest PR `json:"pullRequest"`
-				} `json:"repository"`
-			} `json:"data"`
-		}
-		if err := json.Unmarshal(out, &resp); err != nil {
-			return prRefreshedMsg{err: err}
-		}
-		updated := resp.Data.Repository.PullRequest
+		updated.Host = pr.Host
 		updated.Repository = pr.Repository
-		return prRefreshedMsg{pr: updated}
+		return prRefreshedMsg{prs: []PR{updated}}
 	}
 }
 
+// refreshBatchSize is how many cached PRs share one aliased GraphQL query.
+const refreshBatchSize = 25
+
+func fetchPRBatchCmd(host string, prs []PR) tea.Cmd {
+	// Copy: the caller's slice aliases m.prs, which Update keeps mutating.
+	batch := append([]PR(nil), prs...)
+	return func() tea.Msg {
+		updated, err := backendFor(host).FetchPRs(batch)
+		return prRefreshedMsg{prs: updated, err: err}
+	}
+}
+
+// editorCommand is the editor for review bodies: SUP_EDITOR or the config's
+// editor, then $EDITOR, then vi. It may include arguments ("code --wait").
+func editorCommand() string {
+	for _, e := range []string{opts.Editor, os.Getenv("EDITOR")} {
+		if strings.TrimSpace(e) != "" {
+			return e
+		}
+	}
+	return "vi"
+}
+
+// diffViewerCommand is what the d key pipes a PR's patch to. Plain "hunk"
+// gets hunk's own patch arguments; anything else is run as given.
+func diffViewerCommand() string {
+	if strings.TrimSpace(opts.DiffViewer) != "" {
+		return opts.DiffViewer
+	}
+	return "hunk"
+}
+
 func startEditorCmd(action string, pr PR) (tea.Cmd, error) {
 	f, err := os.CreateTemp("", fmt.Sprintf("sup-review-%d-*.md", pr.Number))
 	if err != nil {
@@ -735,11 +562,8 @@ func startEditorCmd(action string, pr PR) (tea.Cmd, error) {
 	tmpPath := f.Name()
 	f.Close()
 
-	editor := os.Getenv("EDITOR")
-	if editor == "" {
-		editor = "vi"
-	}
-	editorCmd := exec.Command(editor, tmpPath)
+	editor := strings.Fields(editorCommand())
+	editorCmd := exec.Command(editor[0], append(editor[1:], tmpPath)...)
 	return tea.ExecProcess(editorCmd, func(err error) tea.Msg {
 		defer os.Remove(tmpPath)
 		if err != nil {
@@ -754,23 +578,56 @@ func startEditorCmd(action string, pr PR) (tea.Cmd, error) {
 }
 
 func refreshMetaCmd() tea.Msg {
-	if demoMode {
-		return metaRefreshedMsg{orgs: orgs, currentUser: currentUser}
-	}
 	newOrgs := orgs
-	if !mineMode && os.Getenv("SUP_ORG") == "" {
-		if fetched, err := fetchUserOrgs(); err == nil && len(fetched) > 0 {
+	if !mineMode && opts.Org == "" {
+		if fetched, err := fetchAllOrgs(); err == nil && len(fetched) > 0 {
 			newOrgs = fetched
 		}
 	}
-	newUser := getCurrentUser()
+	newUser, _ := backendFor(defaultHost()).CurrentUser()
 	if newUser == "" {
 		newUser = currentUser
 	}
+	if demoMode {
+		return metaRefreshedMsg{orgs: newOrgs, currentUser: newUser}
+	}
 	saveCachedMeta(metaCache{Orgs: newOrgs, CurrentUser: newUser})
 	return metaRefreshedMsg{orgs: newOrgs, currentUser: newUser}
 }
 
+// fetchAllOrgs unions the user's orgs across every known host. Orgs that
+// SUP_HOSTS maps explicitly are always included.
+func fetchAllOrgs() ([]string, error) {
+	seen := map[string]bool{}
+	var result []string
+	add := func(o string) {
+		if !seen[strings.ToLower(o)] {
+			seen[strings.ToLower(o)] = true
+			result = append(result, o)
+		}
+	}
+	var firstErr error
+	for _, h := range knownHosts() {
+		fetched, err := backendFor(h).Orgs()
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		for _, o := range fetched {
+			add(o)
+		}
+	}
+	for o := range orgHosts() {
+		add(o)
+	}
+	if len(result) == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+	return result, nil
+}
+
 type startRefreshMsg struct{}
 
 func (m model) Init() tea.Cmd {
@@ -796,10 +653,14 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if len(m.prs) == 0 {
 				m.err = msg.err
 			}
+			m.refreshErrs++
+			m.lastRefreshErr = msg.err
+			m.failedHosts[m.shards[msg.shardIdx].Host] = true
 			m.pendingShards--
 			if m.pendingShards <= 0 {
 				m.refreshing = false
 				m.loading = false
+				m.viewStates[m.view].refreshed = true
 			}
 			return m, nil
 		}
@@ -829,29 +690,32 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// other shards). Otherwise this shard is done.
 		var next tea.Cmd
 		if msg.hasNext {
-			next = fetchShardPage(msg.shardIdx, msg.endCursor, msg.refreshID)
+			next = fetchShardPage(m.shards[msg.shardIdx], msg.shardIdx, msg.endCursor, msg.refreshID)
 		} else {
 			m.pendingShards--
 		}
 
-		// When every shard has finished, prune PRs not seen this refresh and persist.
+		// When every shard has finished, prune PRs not seen this refresh and
+		// persist. A host whose search failed didn't get to report its PRs,
+		// so they're kept rather than wiped from the cache.
 		if m.pendingShards <= 0 && !msg.hasNext {
 			kept := m.prs[:0]
 			for _, pr := range m.prs {
-				if m.refreshSeen[prKey(pr)] {
+				if m.refreshSeen[prKey(pr)] || m.failedHosts[prHost(pr)] {
 					kept = append(kept, pr)
 				}
 			}
 			m.prs = kept
 			if !demoMode {
-				savePRsToCache(m.prs)
+				saveViewCache(m.view, m.prs)
 			}
 			m.refreshing = false
+			m.viewStates[m.view].refreshed = true
 		}
 
-		sortPRsByOldestFirst(m.prs)
+		sortPRs(m.prs, m.sort)
 
-		if m.filterText != "" {
+		if m.filtering() {
 			m.applyFilter()
 		} else {
 			m.filtered = m.prs
@@ -888,7 +752,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case spinnerTickMsg:
-		if m.loading || m.refreshing || m.loadingDiff || m.actionPending {
+		if m.loading || m.refreshing || m.loadingDiff || m.actionPending || len(m.detailLoading) > 0 || m.worktrees.busy() {
 			m.spinnerFrame = (m.spinnerFrame + 1) % len(spinnerFrames)
 			return m, spinnerTick()
 		}
@@ -908,6 +772,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case reviewSubmittedMsg:
 		m.actionPending = false
+		m.pendingLabel = ""
 		if msg.err != nil {
 			m.actionStatus = "Error: " + msg.err.Error()
 			return m, nil
@@ -920,11 +785,19 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			verb = "Requested changes on"
 		case "comment":
 			verb = "Commented on"
+		case "merge":
+			verb = "Merged"
+		case "auto-merge":
+			verb = "Enabled auto-merge on"
 		}
 		m.actionStatus = fmt.Sprintf("✓ %s PR #%d", verb, msg.pr.Number)
 		// Refresh just this PR so its badge reflects the new review state.
 		return m, fetchSinglePRCmd(msg.pr)
 
+	case mergeInfoMsg:
+		m.startMergePrompt(msg)
+		return m, nil
+
 	case metaRefreshedMsg:
 		if len(msg.orgs) > 0 {
 			orgs = msg.orgs
@@ -936,15 +809,19 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case prRefreshedMsg:
 		if msg.err != nil {
-			// Best-effort; leave the list alone on failure.
+			// Keep whatever did come back, but count the failure for the footer.
+			m.refreshErrs++
+			m.lastRefreshErr = msg.err
+		}
+		if len(msg.prs) == 0 {
 			return m, nil
 		}
-		for i := range m.prs {
-			if m.prs[i].Number == msg.pr.Number &&
-				m.prs[i].Repository.Name == msg.pr.Repository.Name &&
-				m.prs[i].Repository.Owner.Login == msg.pr.Repository.Owner.Login {
-				m.prs[i] = msg.pr
-				break
+		for _, pr := range msg.prs {
+			for i := range m.prs {
+				if prKey(m.prs[i]) == prKey(pr) {
+					m.prs[i] = pr
+					break
+				}
 			}
 		}
 		// Preserve cursor across the filter rebuild.
@@ -952,7 +829,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if len(m.filtered) > 0 && m.cursor < len(m.filtered) {
 			selectedPRNumber = m.filtered[m.cursor].Number
 		}
-		if m.filterText != "" {
+		if m.filtering() {
 			m.applyFilter()
 		} else {
 			m.filtered = m.prs
@@ -972,7 +849,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.cursor = 0
 		}
 		if !demoMode {
-			savePRsToCache(m.prs)
+			saveViewCache(m.view, m.prs)
 		}
 		return m, nil
 
@@ -982,20 +859,86 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.diffError = msg.err.Error()
 			return m, nil
 		}
-		args := []string{"patch"}
-		if msg.mode != "" {
-			args = append(args, "--mode", msg.mode)
+		viewer := strings.Fields(diffViewerCommand())
+		args := viewer[1:]
+		if viewer[0] == "hunk" && len(args) == 0 {
+			args = []string{"patch"}
+			if msg.mode != "" {
+				args = append(args, "--mode", msg.mode)
+			}
+			args = append(args, "-")
 		}
-		args = append(args, "-")
-		hunk := exec.Command("hunk", args...)
-		hunk.Stdin = bytes.NewReader(msg.patch)
-		return m, tea.ExecProcess(hunk, func(err error) tea.Msg {
+		cmd := exec.Command(viewer[0], args...)
+		cmd.Stdin = bytes.NewReader(msg.patch)
+		return m, tea.ExecProcess(cmd, func(err error) tea.Msg {
 			return hunkDoneMsg{err: err}
 		})
 
+	case detailLoadedMsg:
+		delete(m.detailLoading, msg.key)
+		if msg.err != nil {
+			m.detailErrs[msg.key] = msg.err
+		} else {
+			delete(m.detailErrs, msg.key)
+			m.details[msg.key] = msg.detail
+		}
+		return m, nil
+
+	case repoFoundMsg:
+		m.actionPending = false
+		m.pendingLabel = ""
+		switch {
+		case msg.action == "clone" && msg.path != "":
+			m.actionStatus = fmt.Sprintf("%s is already cloned at %s", repoSlug(msg.pr), shortPath(msg.path))
+		case msg.action == "clone":
+			m.startClonePrompt(msg.pr, "")
+		case msg.path == "":
+			m.startClonePrompt(msg.pr, msg.action)
+		default:
+			return m.checkout(msg.pr, msg.action)
+		}
+		return m, nil
+
+	case cloneDoneMsg:
+		if msg.err != nil {
+			m.actionStatus = fmt.Sprintf("Error cloning %s: %v", repoSlug(msg.pr), msg.err)
+			return m, nil
+		}
+		return m.indexClone(msg)
+
+	case cloneIndexedMsg:
+		m.actionPending = false
+		m.pendingLabel = ""
+		if msg.then != "" {
+			return m.checkout(msg.pr, msg.then)
+		}
+		m.actionStatus = fmt.Sprintf("✓ Cloned %s into %s", repoSlug(msg.pr), shortPath(msg.path))
+		return m, nil
+
+	case worktreesLoadedMsg:
+		if m.worktrees != nil {
+			m.worktrees.setList(msg.list)
+		}
+		return m, nil
+
+	case worktreesPrunedMsg:
+		if m.worktrees == nil {
+			return m, nil
+		}
+		m.worktrees.pruning = false
+		m.worktrees.loading = true
+		m.worktrees.status = fmt.Sprintf("✓ Pruned %d worktree(s)", msg.removed)
+		if len(msg.kept) > 0 {
+			m.worktrees.status += fmt.Sprintf("; %s", strings.Join(msg.kept, "; "))
+		}
+		if len(msg.errs) > 0 {
+			m.worktrees.status = changesRequestedStyle.Render(fmt.Sprintf("Pruned %d, %d failed: %v", msg.removed, len(msg.errs), msg.errs[0]))
+		}
+		return m, loadWorktreesCmd()
+
 	case hunkDoneMsg:
 		if msg.err != nil {
-			m.diffError = fmt.Sprintf("hunk error: %v", msg.err)
+			m.diffError = fmt.Sprintf("%s error: %v", strings.Fields(diffViewerCommand())[0], msg.err)
 		}
 		return m, nil
 
@@ -1005,16 +948,24 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.quitting = true
 			return m, tea.Quit
 		}
-		// In help overlay, only ?/esc/q dismiss; everything else is ignored.
+		// In help overlay, only help/esc/quit dismiss; everything else is ignored.
 		if m.helpMode {
-			switch msg.String() {
-			case "?", "esc", "q":
+			if closesOverlay(msg.String(), "help") {
 				m.helpMode = false
 			}
 			return m, nil
 		}
+		if m.checksPR != nil {
+			if closesOverlay(msg.String(), "checks") {
+				m.checksPR = nil
+			}
+			return m, nil
+		}
+		if m.worktrees != nil {
+			return m.handleWorktreesKey(msg)
+		}
 		// Allow quitting even during animation
-		if msg.String() == "q" {
+		if actionFor(msg.String()) == "quit" {
 			m.quitting = true
 			return m, tea.Quit
 		}
@@ -1024,13 +975,12 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 		if m.filterMode {
-			return m.handleFilterInput(msg)
+			return withDetail(m.handleFilterInput(msg))
 		}
 		// Esc clears an active filter first; only quits when nothing to clear.
-		if msg.String() == "esc" {
-			if m.authorFilter != "" || m.statusFilterIndex >= 0 || m.filterText != "" {
-				m.authorFilter = ""
-				m.statusFilterIndex = -1
+		// Open prompts get the key instead, so esc cancels them.
+		if actionFor(msg.String()) == "clear" && m.confirmAction == "" {
+			if m.filterText != "" {
 				m.filterText = ""
 				m.applyFilter()
 				return m, nil
@@ -1038,12 +988,23 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.quitting = true
 			return m, tea.Quit
 		}
-		return m.handleNormalInput(msg)
+		return withDetail(m.handleNormalInput(msg))
 	}
 
 	return m, nil
 }
 
+// withDetail chains a lazy detail fetch onto a key handler's result, so the
+// pane follows the cursor however it moved.
+func withDetail(tm tea.Model, cmd tea.Cmd) (tea.Model, tea.Cmd) {
+	m := tm.(model)
+	detailCmd := m.ensureDetail()
+	if detailCmd == nil {
+		return m, cmd
+	}
+	return m, tea.Batch(cmd, detailCmd, spinnerTick())
+}
+
 func (m model) handleFilterInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.Type {
 	case tea.KeyEsc:
@@ -1080,114 +1041,42 @@ func (m model) handleFilterInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	}
 }
 
+// applyFilter re-parses filterText, adds the view's own filter and
+// re-filters. While the query doesn't parse (usually mid-edit) the last good
+// one stays in effect and the error is shown on the filter line.
 func (m *model) applyFilter() {
-	// If we have both author and status filters, apply them simultaneously
-	if m.authorFilter != "" && m.statusFilterIndex >= 0 {
-		m.filtered = nil
-		statusFilter := statusFilters[m.statusFilterIndex]
-			
-		// Parse author filter (e.g., "!username" or "@username")
-		authorPrefix := ""
-		authorName := m.authorFilter
-		if strings.HasPrefix(authorName, "!") {
-			authorPrefix = "!"
-			authorName = strings.TrimPrefix(authorName, "!")
-		} else if strings.HasPrefix(authorName, "@") {
-			authorPrefix = "@"
-			authorName = strings.TrimPrefix(authorName, "@")
-		}
-		authorName = strings.ToLower(authorName)
-		
-		for _, pr := range m.prs {
-			// Check author filter
-			authorMatch := false
-			if authorPrefix == "" {
-				// No author filter
-				authorMatch = true
-			} else if authorPrefix == "!" {
-				// Filter by author
-				authorMatch = strings.Contains(strings.ToLower(pr.Author.Login), authorName)
-			} else if authorPrefix == "@" {
-				// Filter by reviewer
-				requested := strings.ToLower(getRequestedReviewerNames(pr))
-				authorMatch = requested != "" && strings.Contains(requested, authorName)
-			}
-			
-			if !authorMatch {
-				continue
-			}
-			
-			// Check status filter
-			if statusLabelForFilter(pr) == statusFilter {
-				m.filtered = append(m.filtered, pr)
-			}
-		}
-		m.cursor = 0
-		return
-	}
-	
-	// Fall back to legacy behavior for backwards compatibility
-	if m.filterText == "" {
-		m.filtered = m.prs
-		m.cursor = 0
-		m.statusFilterIndex = -1
-		return
-	}
-
-	// Reset status filter index - will be updated if filter matches a status
-	m.statusFilterIndex = -1
-
-	filter := strings.ToLower(m.filterText)
-	m.filtered = nil
-
-	// @username prefix: match requested reviewers only
-	if strings.HasPrefix(filter, "@") {
-		userFilter := strings.TrimPrefix(filter, "@")
-		for _, pr := range m.prs {
-			requested := strings.ToLower(getRequestedReviewerNames(pr))
-			if requested != "" && strings.Contains(requested, userFilter) {
-				m.filtered = append(m.filtered, pr)
-			}
-		}
-		m.cursor = 0
-		return
-	}
-
-	// !username prefix: match author only
-	if strings.HasPrefix(filter, "!") {
-		userFilter := strings.TrimPrefix(filter, "!")
-		for _, pr := range m.prs {
-			if strings.Contains(strings.ToLower(pr.Author.Login), userFilter) {
-				m.filtered = append(m.filtered, pr)
-			}
-		}
-		m.cursor = 0
-		return
-	}
-
-	// Check if filter matches a status filter
-	for i, status := range statusFilters {
-		if filter == status {
-			m.statusFilterIndex = i
-		}
+	if expr, err := parseFilter(m.filterText); err != nil {
+		m.filterErr = err
+	} else {
+		m.filter = withViewFilter(views[m.view].filter, expr)
+		m.filterErr = nil
 	}
-
-	// Default: search all fields including reviewer
-	for _, pr := range m.prs {
-		statusLabel := statusLabelForFilter(pr)
-		reviewers := getAllReviewerNames(pr)
-		searchText := strings.ToLower(fmt.Sprintf("%s %s %s %s %s %s #%d %d %s",
-			pr.Repository.Name, pr.Title, pr.Author.Login, pr.HeadRefName, statusLabel,
-			pr.Repository.Owner.Login, pr.Number, pr.Number, reviewers))
-		if strings.Contains(searchText, filter) {
-			m.filtered = append(m.filtered, pr)
-		}
+	m.filtered = filterPRs(m.prs, m.filter)
+	// An explicit sort: term wins over fuzzy ranking.
+	if spec := filterSort(m.filter); spec != nil {
+		sortPRs(m.filtered, spec)
+	} else {
+		rankPRs(m.filtered, textTerms(m.filter))
 	}
 	m.cursor = 0
 }
 
+// filtering reports whether a filter, typed or the view's, narrows the list.
+func (m model) filtering() bool {
+	return m.filterText != "" || views[m.view].filter != nil
+}
+
 func (m model) handleNormalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	// Confirmation prompts intercept input before any other handling.
+	if m.confirmAction == "merge" {
+		return m.handleMergeKey(msg)
+	}
+	if m.confirmAction == "copy" {
+		return m.handleCopyKey(msg)
+	}
+	if m.confirmAction == "clone" {
+		return m.handleCloneKey(msg)
+	}
 	if m.confirmAction != "" {
 		switch msg.String() {
 		case "y", "Y":
@@ -1205,9 +1094,12 @@ func (m model) handleNormalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	act := actionFor(msg.String())
+
 	// Handle status filter cycling
-	if msg.String() == "s" {
-		m.cycleStatusFilter()
+	if act == "cycle-status" {
+		m.filterText = cycleStatusTerm(m.filterText)
+		m.applyFilter()
 		return m, nil
 	}
 
@@ -1215,83 +1107,136 @@ func (m model) handleNormalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	m.diffError = ""
 	m.actionStatus = ""
 
-	switch msg.String() {
-	case "up", "k":
+	switch act {
+	case "up":
 		if m.cursor > 0 {
 			m.cursor--
 		}
 		return m, nil
 
-	case "down", "j":
+	case "down":
 		if m.cursor < len(m.filtered)-1 {
 			m.cursor++
 		}
 		return m, nil
 
-	case "g":
+	case "top":
 		m.cursor = 0
 		return m, nil
 
-	case "G":
+	case "bottom":
 		if len(m.filtered) > 0 {
 			m.cursor = len(m.filtered) - 1
 		}
 		return m, nil
 
-	case "a":
+	case "toggle-mine":
 		if currentUser != "" {
-			if m.authorFilter == "!"+currentUser {
-				// Toggle off same filter
-				m.authorFilter = ""
-			} else {
-				// Set author filter
-				m.authorFilter = "!" + currentUser
-			}
+			m.filterText = toggleFilterTerm(m.filterText, "author:@me")
 			m.applyFilter()
 		}
 		return m, nil
 
-	case "r":
+	case "toggle-review":
 		if currentUser != "" {
-			if m.authorFilter == "@"+currentUser {
-				// Toggle off same filter
-				m.authorFilter = ""
-			} else {
-				// Set reviewer filter
-				m.authorFilter = "@" + currentUser
-			}
+			m.filterText = toggleFilterTerm(m.filterText, "review:@me")
 			m.applyFilter()
 		}
 		return m, nil
 
-	case "R":
+	case "refresh":
 		m.refreshing = true
 		return m, m.startRefresh()
 
-	case "/":
+	case "sort":
+		m.setSort(nextSort(m.sort))
+		return m, nil
+
+	case "reverse-sort":
+		m.setSort(reverseSort(m.sort))
+		return m, nil
+
+	case "prev-view":
+		return m, m.switchView((m.view + len(views) - 1) % len(views))
+
+	case "next-view":
+		return m, m.switchView((m.view + 1) % len(views))
+
+	case "view-1", "view-2", "view-3", "view-4", "view-5", "view-6", "view-7", "view-8", "view-9":
+		return m, m.switchView(int(act[len(act)-1] - '1'))
+
+	case "filter":
+		// Open with the current query so a/r/s terms can be edited.
 		m.filterMode = true
-		m.filterText = ""
 		return m, nil
 
-	case "?":
+	case "help":
 		m.helpMode = true
 		return m, nil
 
-	case "enter":
+	case "detail":
+		m.detailOpen = !m.detailOpen
+		return m, nil
+
+	case "detail-down":
+		if m.detailOpen {
+			m.detailScroll += m.detailHeight() / 2
+		}
+		return m, nil
+
+	case "detail-up":
+		if m.detailOpen {
+			m.detailScroll -= m.detailHeight() / 2
+			if m.detailScroll < 0 {
+				m.detailScroll = 0
+			}
+		}
+		return m, nil
+
+	case "clone":
+		if m.actionPending || m.loadingDiff {
+			return m, nil
+		}
 		if len(m.filtered) > 0 && m.cursor < len(m.filtered) {
-			m.selected = &m.filtered[m.cursor]
-			m.quitting = true
-			return m, tea.Quit
+			return m.findRepo(m.filtered[m.cursor], act)
 		}
 		return m, nil
 
-	case "d":
+	case "worktrees":
+		m.worktrees = &worktreesScreen{loading: true}
+		return m, tea.Batch(loadWorktreesCmd(), spinnerTick())
+
+	case "checks":
+		if len(m.filtered) > 0 && m.cursor < len(m.filtered) {
+			pr := m.filtered[m.cursor]
+			m.checksPR = &pr
+		}
+		return m, nil
+
+	case "checkout", "checkout-worktree":
+		if m.actionPending || m.loadingDiff {
+			return m, nil
+		}
+		if len(m.filtered) > 0 && m.cursor < len(m.filtered) {
+			if demoMode {
+				return m.checkout(m.filtered[m.cursor], act)
+			}
+			return m.findRepo(m.filtered[m.cursor], act)
+		}
+		return m, nil
+
+	case "diff":
 		if m.loadingDiff {
 			return m, nil
 		}
 		if len(m.filtered) > 0 && m.cursor < len(m.filtered) {
-			if _, err := exec.LookPath("hunk"); err != nil {
-				m.diffError = "hunk not installed — npm i -g hunkdiff"
+			if viewer := strings.Fields(diffViewerCommand())[0]; viewer == "hunk" {
+				if _, err := exec.LookPath("hunk"); err != nil {
+					m.diffError = "hunk not installed — npm i -g hunkdiff"
+					return m, nil
+				}
+			} else if _, err := exec.LookPath(viewer); err != nil {
+				m.diffError = viewer + " not installed"
 				return m, nil
 			}
 			m.diffError = ""
@@ -1300,7 +1245,7 @@ func (m model) handleNormalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "A":
+	case "approve":
 		if m.actionPending || m.loadingDiff {
 			return m, nil
 		}
@@ -1311,7 +1256,7 @@ func (m model) handleNormalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "D":
+	case "request-changes":
 		if m.actionPending || m.loadingDiff {
 			return m, nil
 		}
@@ -1325,7 +1270,18 @@ func (m model) handleNormalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "M":
+	case "merge":
+		if m.actionPending || m.loadingDiff {
+			return m, nil
+		}
+		if len(m.filtered) > 0 && m.cursor < len(m.filtered) {
+			m.actionPending = true
+			m.pendingLabel = "Checking merge options..."
+			return m, tea.Batch(fetchMergeInfoCmd(m.filtered[m.cursor]), spinnerTick())
+		}
+		return m, nil
+
+	case "comment":
 		if m.actionPending || m.loadingDiff {
 			return m, nil
 		}
@@ -1339,32 +1295,28 @@ func (m model) handleNormalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "o":
+	case "open":
 		if len(m.filtered) > 0 && m.cursor < len(m.filtered) {
-			pr := m.filtered[m.cursor]
-			url := fmt.Sprintf("https://github.com/%s/%s/pull/%d", pr.Repository.Owner.Login, pr.Repository.Name, pr.Number)
-			exec.Command("open", "-g", url).Start()
+			if err := openURL(prURL(m.filtered[m.cursor])); err != nil {
+				m.actionStatus = "Error opening link: " + err.Error()
+			}
 		}
 		return m, nil
 
-	case "c":
+	case "copy":
 		if len(m.filtered) > 0 && m.cursor < len(m.filtered) {
 			pr := m.filtered[m.cursor]
-			url := fmt.Sprintf("https://github.com/%s/%s/pull/%d", pr.Repository.Owner.Login, pr.Repository.Name, pr.Number)
-			cmd := exec.Command("pbcopy")
-			cmd.Stdin = strings.NewReader(url)
-			if err := cmd.Run(); err != nil {
-				m.actionStatus = "Error copying link: " + err.Error()
-			} else {
-				m.actionStatus = fmt.Sprintf("✓ Copied PR #%d link", pr.Number)
-			}
+			m.confirmAction = "copy"
+			m.confirmPR = &pr
 		}
 		return m, nil
 
-	case "O":
+	case "open-all":
 		for _, pr := range m.filtered {
-			url := fmt.Sprintf("https://github.com/%s/%s/pull/%d", pr.Repository.Owner.Login, pr.Repository.Name, pr.Number)
-			exec.Command("open", "-g", url).Start()
+			if err := openURL(prURL(pr)); err != nil {
+				m.actionStatus = "Error opening links: " + err.Error()
+				break
+			}
 		}
 		return m, nil
 	}
@@ -1374,24 +1326,6 @@ func (m model) handleNormalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 var statusFilters = []string{"draft", "approved", "denied", "review", "commented", "open"}
 
-func (m *model) cycleStatusFilter() {
-	m.statusFilterIndex = (m.statusFilterIndex + 1) % (len(statusFilters) + 1)
-	if m.statusFilterIndex >= len(statusFilters) {
-		// Cycle back to no filter
-		if m.authorFilter != "" {
-			// Keep only author filter
-			m.statusFilterIndex = -1
-		} else {
-			// Clear everything
-			m.filterText = ""
-			m.statusFilterIndex = -1
-		}
-	} else {
-		// Set status filter
-	}
-	m.applyFilter()
-}
-
 func getStatusBadge(pr PR) string {
 	if pr.IsDraft {
 		return draftStyle.Render("[Draft]")
@@ -1513,57 +1447,59 @@ func getDiffStats(pr PR) string {
 
 func (m model) helpView() string {
 	var s strings.Builder
-	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
-	sectionStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("141"))
-	keyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("220"))
-	descStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("252"))
-	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
-
-	sections := []struct {
-		title string
-		items [][2]string
-	}{
-		{"Navigation", [][2]string{
-			{"j / ↓", "Move down"},
-			{"k / ↑", "Move up"},
-			{"g / G", "Top / bottom"},
-		}},
-		{"Filter", [][2]string{
-			{"/", "Open filter"},
-			{"s", "Cycle status filter"},
-			{"@user", "Filter by reviewer"},
-			{"!user", "Filter by author"},
-			{"a", "My PRs"},
-			{"r", "My reviews"},
-		}},
-		{"Actions", [][2]string{
-			{"Enter", "Checkout PR"},
-			{"d", "Review diff (hunk: 1=split · 2=stack · 0=auto)"},
-			{"A", "Approve"},
-			{"D", "Request changes"},
-			{"M", "Comment"},
-			{"o", "Open in browser"},
-			{"O", "Open all needing review"},
-			{"c", "Copy PR link"},
-		}},
-		{"Other", [][2]string{
-			{"R", "Refresh PR list"},
-			{"?", "Toggle this help"},
-			{"esc", "Clear filter (or quit)"},
-			{"q", "Quit"},
-		}},
-	}
-
-	const keyCol = 12
-	s.WriteString("\n  " + headerStyle.Render("sup — keybindings") + "\n\n")
-	for _, sec := range sections {
-		s.WriteString("  " + sectionStyle.Render(sec.title) + "\n")
-		for _, item := range sec.items {
-			s.WriteString("    " + keyStyle.Render(pad(item[0], keyCol)) + descStyle.Render(item[1]) + "\n")
+
+	// Rows come from the keymap, in table order, grouped by section; the
+	// filter syntax, which isn't keys, follows its section.
+	type row struct {
+		keys []string
+		help string
+	}
+	var titles []string
+	rows := map[string][]row{}
+	var last *action
+	for i, a := range actions {
+		keys := keysFor(a.name)
+		if len(keys) == 0 {
+			continue
+		}
+		if _, ok := rows[a.section]; !ok {
+			titles = append(titles, a.section)
+		}
+		sec := rows[a.section]
+		if last != nil && last.section == a.section && last.help == a.help && len(sec) > 0 {
+			// Same help as the row above: fold the keys into it.
+			prev := &sec[len(sec)-1]
+			prev.keys = append(prev.keys, keys...)
+		} else {
+			sec = append(sec, row{keys: keys, help: a.help})
+		}
+		rows[a.section] = sec
+		last = &actions[i]
+	}
+
+	keyCol := 12
+	for _, sec := range rows {
+		for _, r := range sec {
+			keyCol = max(keyCol, displayWidth(keysLabel(r.keys))+2)
+		}
+	}
+	for _, item := range filterSyntaxHelp {
+		keyCol = max(keyCol, displayWidth(item[0])+2)
+	}
+	s.WriteString("\n  " + titleStyle.Render("sup — keybindings") + "\n\n")
+	for _, title := range titles {
+		s.WriteString("  " + sectionStyle.Render(title) + "\n")
+		for _, r := range rows[title] {
+			s.WriteString("    " + keyStyle.Render(pad(keysLabel(r.keys), keyCol)) + normalStyle.Render(r.help) + "\n")
+		}
+		if title == "Filter" {
+			for _, item := range filterSyntaxHelp {
+				s.WriteString("    " + keyStyle.Render(pad(item[0], keyCol)) + normalStyle.Render(item[1]) + "\n")
+			}
 		}
 		s.WriteString("\n")
 	}
-	s.WriteString("  " + dimStyle.Render("? · esc · q to close") + "\n")
+	s.WriteString("  " + dimStyle.Render(closeHint("help")) + "\n")
 	return s.String()
 }
 
@@ -1571,58 +1507,43 @@ func (m model) View() string {
 	if m.helpMode {
 		return m.helpView()
 	}
+	if m.checksPR != nil {
+		return m.checksView()
+	}
+	if m.worktrees != nil {
+		return m.worktreesView()
+	}
 	var s strings.Builder
-	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
-	loadingStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
-
-	// Fixed column widths
-	const (
-		colStatus   = 12
-		colNum      = 6
-		colAuthor   = 14
-		colReviewer = 14
-		colDiff     = 16 // approximate width for +/- stats
-		colPadding  = 4  // cursor + spacing
-	)
-
-	// Calculate dynamic column widths based on terminal width
-	fixedWidth := colStatus + colNum + colAuthor + colReviewer + colDiff + colPadding
-	flexWidth := m.width - fixedWidth
-	if flexWidth < 60 {
-		flexWidth = 60 // minimum for flexible columns
-	}
-
-	// Distribute flexible space: 30% repo, 40% title, 30% branch
-	colRepo := flexWidth * 30 / 100
-	colTitle := flexWidth * 40 / 100
-	colBranch := flexWidth - colRepo - colTitle
 
-	s.WriteString("\n")
-	separatorWidth := m.width - 2 // account for "  " prefix
-	if separatorWidth < 60 {
-		separatorWidth = 60
+	width := m.width
+	if width == 0 {
+		width = 120 // until the first WindowSizeMsg
 	}
+	cols, widths := layoutColumns(viewColumns(m.view), width)
+
+	s.WriteString(m.tabsView() + "\n")
+	separatorWidth := width - 2 // account for "  " prefix
 	rowWidth := separatorWidth
 
+	// The view's own filter shows dimmed ahead of what was typed.
+	base := ""
+	if vf := views[m.view].Filter; vf != "" {
+		base = dimStyle.Render(vf) + " "
+	}
 	filterLine := "  "
 	if m.filterMode {
-		filterLine = fmt.Sprintf("  / %s█", m.filterText)
-	} else if m.authorFilter != "" && m.statusFilterIndex >= 0 {
-		// Show both author and status filters
-		statusFilter := statusFilters[m.statusFilterIndex]
-		filterLine = fmt.Sprintf("  Filter: %s %s", m.authorFilter, statusFilter)
-	} else if m.authorFilter != "" {
-		filterLine = fmt.Sprintf("  Filter: %s", m.authorFilter)
-	} else if m.statusFilterIndex >= 0 {
-		statusFilter := statusFilters[m.statusFilterIndex]
-		filterLine = fmt.Sprintf("  Filter: %s", statusFilter)
-	} else if m.filterText != "" {
-		filterLine = fmt.Sprintf("  Filter: %s", m.filterText)
+		filterLine = fmt.Sprintf("  / %s%s█", base, m.filterText)
+	} else if m.filterText != "" || base != "" {
+		filterLine = fmt.Sprintf("  Filter: %s%s", base, m.filterText)
 	}
 	switch {
 	case m.actionPending:
 		spinner := spinnerFrames[m.spinnerFrame]
-		s.WriteString(loadingStyle.Render("  " + spinner + " Submitting review..."))
+		label := m.pendingLabel
+		if label == "" {
+			label = "Submitting review..."
+		}
+		s.WriteString(loadingStyle.Render("  " + spinner + " " + label))
 	case m.actionStatus != "":
 		style := approvedStyle
 		if strings.HasPrefix(m.actionStatus, "Error") || strings.Contains(m.actionStatus, "cancelled") {
@@ -1633,13 +1554,21 @@ func (m model) View() string {
 		s.WriteString(changesRequestedStyle.Render("  " + m.diffError))
 	case filterLine == "  ":
 		s.WriteString(filterLine)
+	case m.filterErr != nil:
+		s.WriteString(filterStyle.Render(filterLine) + changesRequestedStyle.Render("  ✗ "+m.filterErr.Error()))
 	default:
 		s.WriteString(filterStyle.Render(filterLine))
 	}
 	s.WriteString("\n")
 
 	// Always show header
-	s.WriteString(dimStyle.Render("  " + pad("STATUS", colStatus) + pad("REPO", colRepo) + pad("#", colNum) + pad("TITLE", colTitle) + pad("AUTHOR", colAuthor) + pad("REVIEWER", colReviewer) + pad("BRANCH", colBranch) + "+/-"))
+	header := "  "
+	rowPlainWidth := 2
+	for i, c := range cols {
+		header += pad(c.header, widths[i])
+		rowPlainWidth += widths[i]
+	}
+	s.WriteString(dimStyle.Render(strings.TrimRight(header, " ")))
 	s.WriteString("\n")
 	s.WriteString(dimStyle.Render("  " + strings.Repeat("─", separatorWidth)))
 	s.WriteString("\n")
@@ -1661,9 +1590,12 @@ func (m model) View() string {
 		s.WriteString("  No PRs found.\n")
 	} else {
 		// Calculate visible range
-		visibleItems := m.height - 8
+		visibleItems := m.height - 8 - m.detailHeight()
 		if visibleItems < 5 {
 			visibleItems = 15
+			if m.detailOpen {
+				visibleItems = 5
+			}
 		}
 
 		start := 0
@@ -1679,78 +1611,46 @@ func (m model) View() string {
 			end = m.visibleCount
 		}
 
+		terms := textTerms(m.filter)
+
 		for i := start; i < end; i++ {
 			pr := m.filtered[i]
 			isSelected := m.cursor == i
+			hl := highlights(pr, terms)
 
 			cursor := "  "
 			if isSelected {
 				cursor = "» "
 			}
 
-			// Prepare padded values
-			statusPlain := pad(stripAnsi(getStatusBadge(pr)), colStatus)
-			repo := pad(truncate(pr.Repository.Name, colRepo-1), colRepo)
-			num := pad(fmt.Sprintf("#%d", pr.Number), colNum)
-			title := pad(truncate(pr.Title, colTitle-1), colTitle)
-			author := pad(truncate(pr.Author.Login, colAuthor-1), colAuthor)
-			reviewer := pad(truncate(getReviewer(pr), colReviewer-1), colReviewer)
-			branch := pad(truncate(pr.HeadRefName, colBranch-1), colBranch)
-			leftDiff := (colDiff - 1) / 2
-			rightDiff := colDiff - 1 - leftDiff
-			addsPlain := fmt.Sprintf("+%d", pr.Additions)
-			delsPlain := fmt.Sprintf("-%d", pr.Deletions)
-			addsPadded := padLeft(addsPlain, leftDiff)
-			delsPadded := padLeft(delsPlain, rightDiff)
-			diffPlain := addsPadded + " " + delsPadded
-			rowPlain := cursor + statusPlain + repo + num + title + author + reviewer + branch + diffPlain
-
 			if isSelected {
 				s.WriteString(caretStyle.Render(cursor))
-				s.WriteString(getSelectedStatusBadge(pr) + strings.Repeat(" ", colStatus-len(stripAnsi(getSelectedStatusBadge(pr)))))
-				s.WriteString(selectedNormalStyle.Render(repo))
-				s.WriteString(selectedNormalStyle.Render(num))
-				s.WriteString(selectedNormalStyle.Render(title))
-				s.WriteString(selectedStyle.Render(author))
-				s.WriteString(selectedReviewRequestedStyle.Render(reviewer))
-				s.WriteString(selectedBranchStyle.Render(branch))
-				s.WriteString(selectedAdditionsStyle.Render(addsPadded))
-				s.WriteString(" ")
-				s.WriteString(selectedDeletionsStyle.Render(delsPadded))
-
-				currentWidth := displayWidth(rowPlain)
-				if currentWidth < rowWidth {
-					s.WriteString(strings.Repeat(" ", rowWidth-currentWidth))
-				}
 			} else {
 				s.WriteString(cursor)
-				// Apply colors after padding
-				s.WriteString(getStatusBadge(pr) + strings.Repeat(" ", colStatus-len(stripAnsi(getStatusBadge(pr)))))
-				s.WriteString(normalStyle.Render(repo))
-				s.WriteString(normalStyle.Render(num))
-				s.WriteString(normalStyle.Render(title))
-				s.WriteString(dimStyle.Render(author))
-				s.WriteString(reviewRequestedStyle.Render(reviewer))
-				s.WriteString(branchStyle.Render(branch))
-				s.WriteString(additionsStyle.Render(addsPadded))
-				s.WriteString(" ")
-				s.WriteString(deletionsStyle.Render(delsPadded))
-
-				currentWidth := displayWidth(rowPlain)
-				if currentWidth < rowWidth {
-					s.WriteString(strings.Repeat(" ", rowWidth-currentWidth))
-				}
+			}
+			for c, col := range cols {
+				s.WriteString(col.cell(pr, widths[c], isSelected, hl))
+			}
+			if rowPlainWidth < rowWidth {
+				s.WriteString(strings.Repeat(" ", rowWidth-rowPlainWidth))
 			}
 			s.WriteString("\n")
 		}
 
-		if m.filterText != "" {
+		if m.filtering() {
 			s.WriteString(fmt.Sprintf("\n  %d of %d PRs", len(m.filtered), len(m.prs)))
 		} else {
 			s.WriteString(fmt.Sprintf("\n  %d PRs", len(m.prs)))
 		}
+		s.WriteString(dimStyle.Render(" · sort " + m.sortLabel()))
 		if m.confirmAction == "approve" && m.confirmPR != nil {
 			s.WriteString(filterStyle.Render(fmt.Sprintf("  Approve PR #%d? (y/n)", m.confirmPR.Number)))
+		} else if m.confirmAction == "merge" && m.confirmPR != nil {
+			s.WriteString(filterStyle.Render(m.mergePromptView()))
+		} else if m.confirmAction == "copy" && m.confirmPR != nil {
+			s.WriteString(filterStyle.Render(m.copyPromptView()))
+		} else if m.confirmAction == "clone" && m.confirmPR != nil {
+			s.WriteString(filterStyle.Render(m.clonePromptView()))
 		} else if m.refreshing {
 			spinner := spinnerFrames[m.spinnerFrame]
 			s.WriteString(loadingStyle.Render("  " + spinner + " Refreshing"))
@@ -1758,10 +1658,20 @@ func (m model) View() string {
 			spinner := spinnerFrames[m.spinnerFrame]
 			s.WriteString(loadingStyle.Render("  " + spinner + " Loading diff..."))
 		}
+		if notice := rateLimitNotice(); notice != "" {
+			s.WriteString(loadingStyle.Render("  " + notice))
+		}
+		if m.refreshErrs > 0 && m.lastRefreshErr != nil {
+			s.WriteString(changesRequestedStyle.Render(fmt.Sprintf("  ⚠ %d failed to refresh: %s", m.refreshErrs, truncate(strings.Join(strings.Fields(m.lastRefreshErr.Error()), " "), 60))))
+		}
+		if m.detailOpen {
+			s.WriteString("\n")
+			s.WriteString(m.detailView(rowWidth))
+		}
 	}
 
 	s.WriteString("\n")
-	s.WriteString(helpStyle.Render(truncateToWidth("  ?: help · /: filter · enter: checkout · q: quit", rowWidth)))
+	s.WriteString(helpStyle.Render(truncateToWidth("  "+footerHint(), rowWidth)))
 	s.WriteString("\n")
 
 	return s.String()
@@ -1836,22 +1746,21 @@ func stripAnsi(s string) string {
 	return result
 }
 
-func main() {
-	// Parse flags
-	for _, arg := range os.Args[1:] {
-		switch arg {
-		case "--demo":
-			demoMode = true
-		case "--mine", "-m":
-			mineMode = true
-		}
-	}
-
+// initSession sets up backends and works out which orgs and user to query.
+// It's shared by the TUI and the non-interactive subcommands.
+func initSession() error {
 	// Load gh auth token once for direct GraphQL HTTP calls.
-	if !demoMode {
-		if err := loadGHToken(); err != nil {
-			fmt.Fprintln(os.Stderr, "Error: failed to read gh auth token. Run: gh auth login")
-			os.Exit(1)
+	if demoMode {
+		fake := newFakeBackend(mockPRs())
+		fake.orgs = []string{"acme-corp"}
+		newBackend = func(string) Backend { return fake }
+	} else {
+		host := defaultHost()
+		if err := backendFor(host).(*githubBackend).loadToken(); err != nil {
+			if host == githubDotCom {
+				return fmt.Errorf("failed to read gh auth token. Run: gh auth login")
+			}
+			return fmt.Errorf("failed to read gh auth token for %s. Run: gh auth login --hostname %s", host, host)
 		}
 	}
 
@@ -1862,15 +1771,15 @@ func main() {
 		if meta, ok := loadCachedMeta(); ok && meta.CurrentUser != "" {
 			currentUser = meta.CurrentUser
 		} else {
-			currentUser = getCurrentUser()
+			currentUser, _ = backendFor(defaultHost()).CurrentUser()
 			saveCachedMeta(metaCache{CurrentUser: currentUser})
 		}
-	} else if orgEnv := os.Getenv("SUP_ORG"); orgEnv != "" {
-		orgs = strings.Split(orgEnv, ",")
+	} else if opts.Org != "" {
+		orgs = strings.Split(opts.Org, ",")
 		if meta, ok := loadCachedMeta(); ok && meta.CurrentUser != "" {
 			currentUser = meta.CurrentUser
 		} else {
-			currentUser = getCurrentUser()
+			currentUser, _ = backendFor(defaultHost()).CurrentUser()
 			saveCachedMeta(metaCache{Orgs: orgs, CurrentUser: currentUser})
 		}
 	} else if meta, ok := loadCachedMeta(); ok && len(meta.Orgs) > 0 {
@@ -1880,70 +1789,50 @@ func main() {
 	} else {
 		// First run — block on org/user lookup so we have something to query.
 		var err error
-		orgs, err = fetchUserOrgs()
+		orgs, err = fetchAllOrgs()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			fmt.Fprintln(os.Stderr, "Make sure you're logged in with: gh auth login")
-			os.Exit(1)
+			return fmt.Errorf("%v\nMake sure you're logged in with: gh auth login", err)
 		}
 		if len(orgs) == 0 {
-			fmt.Fprintln(os.Stderr, "No organizations found. Use --mine to see your PRs, or set SUP_ORG.")
-			os.Exit(1)
+			return fmt.Errorf("no organizations found. Use --mine to see your PRs, or set SUP_ORG")
 		}
-		currentUser = getCurrentUser()
+		currentUser, _ = backendFor(defaultHost()).CurrentUser()
 		saveCachedMeta(metaCache{Orgs: orgs, CurrentUser: currentUser})
 	}
+	return nil
+}
+
+func main() {
+	os.Exit(runCLI(os.Args[1:]))
+}
+
+// runTUI runs the interactive picker and checks out whatever was selected.
+func runTUI() int {
+	if err := initSession(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
 
 	p := tea.NewProgram(initialModel(), tea.WithAltScreen())
 	finalModel, err := p.Run()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		return 1
 	}
 
 	m := finalModel.(model)
 	if demoMode {
-		return
+		return 0
 	}
 
-	if m.selected != nil {
-		pr := m.selected
-		repoPath := findRepoPath(pr.Repository.Name)
-
-		if repoPath == "" {
-			fmt.Fprintf(os.Stderr, "Repo '%s' not found in common locations.\n", pr.Repository.Name)
-			fmt.Fprintf(os.Stderr, "Clone it: gh repo clone %s/%s\n",
-				pr.Repository.Owner.Login, pr.Repository.Name)
-			fmt.Fprintf(os.Stderr, "Or set SUP_DEV_DIR to your repos directory.\n")
-			os.Exit(1)
-		}
-
-		// Check if the branch is already checked out in a worktree
-		targetPath := repoPath
-		if wtPath := findWorktreePath(repoPath, pr.HeadRefName); wtPath != "" {
-			fmt.Printf("Branch '%s' already checked out at %s\n", pr.HeadRefName, wtPath)
-			targetPath = wtPath
-		} else {
-			// Change to repo directory and run gh pr checkout
-			if err := os.Chdir(repoPath); err != nil {
-				fmt.Fprintf(os.Stderr, "Error: could not cd to %s: %v\n", repoPath, err)
-				os.Exit(1)
-			}
-
-			fmt.Printf("Checking out PR #%d in %s...\n", pr.Number, repoPath)
-			cmd := exec.Command("gh", "pr", "checkout", fmt.Sprintf("%d", pr.Number), "--force")
-			cmd.Stdout = os.Stdout
-			cmd.Stderr = os.Stderr
-			if err := cmd.Run(); err != nil {
-				fmt.Fprintf(os.Stderr, "Error: gh pr checkout failed: %v\n", err)
-				os.Exit(1)
-			}
-		}
-
-		// Write path for shell wrapper to cd into
-		os.WriteFile(selectionFile, []byte(targetPath), 0644)
-	} else {
+	if m.selected == nil {
 		// No selection - clean up any stale selection file
 		os.Remove(selectionFile)
+		return 0
+	}
+	if err := checkoutPR(*m.selected, m.inWorktree); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
 	}
+	return 0
 }
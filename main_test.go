@@ -0,0 +1,276 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newTestModel points every host at a fake backend serving prs and returns
+// a model for the built-in view, as the TUI starts it.
+func newTestModel(t *testing.T, prs []PR) (model, *fakeBackend) {
+	t.Helper()
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	fake := newFakeBackend(prs)
+	fake.orgs = []string{"acme-corp"}
+	fake.user = "sarah"
+
+	oldNewBackend, oldDemo, oldOrgs, oldUser, oldViews := newBackend, demoMode, orgs, currentUser, views
+	reset := func() {
+		backendsMu.Lock()
+		backends = map[string]Backend{}
+		backendsMu.Unlock()
+		repoIndexMu.Lock()
+		repoIndexMem, repoIndexBuilt = nil, false
+		repoIndexMu.Unlock()
+	}
+	t.Cleanup(func() {
+		newBackend, demoMode, orgs, currentUser, views = oldNewBackend, oldDemo, oldOrgs, oldUser, oldViews
+		reset()
+	})
+	newBackend = func(string) Backend { return fake }
+	reset()
+	demoMode = true // keeps the caches out of it
+	orgs = []string{"acme-corp"}
+	views = []viewConfig{{Name: "All"}}
+
+	return initialModel(), fake
+}
+
+// run runs cmd and feeds what it sends back through Update until nothing
+// is left to do. Ticks are dropped, so animations and spinners stop.
+func run(t *testing.T, m model, cmd tea.Cmd) model {
+	t.Helper()
+	queue := []tea.Cmd{cmd}
+	for len(queue) > 0 {
+		cmd, queue = queue[0], queue[1:]
+		if cmd == nil {
+			continue
+		}
+		switch msg := cmd().(type) {
+		case tea.BatchMsg:
+			queue = append(queue, msg...)
+		case tickMsg, spinnerTickMsg, nil:
+		default:
+			next, more := m.Update(msg)
+			m = next.(model)
+			queue = append(queue, more)
+		}
+	}
+	return m
+}
+
+func press(t *testing.T, m model, key string) model {
+	t.Helper()
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+	return run(t, next.(model), cmd)
+}
+
+func testPR(number int, title string) PR {
+	pr := PR{Number: number, Title: title, HeadRefName: fmt.Sprintf("feature/%d", number)}
+	pr.Repository.Name = "api"
+	pr.Repository.Owner.Login = "acme-corp"
+	return pr
+}
+
+func TestUpdateInitialLoad(t *testing.T) {
+	m, fake := newTestModel(t, []PR{testPR(1, "One"), testPR(2, "Two"), testPR(3, "Three")})
+	fake.pageSize = 2 // two pages
+
+	if !m.loading {
+		t.Fatal("model with nothing cached should start loading")
+	}
+	m = run(t, m, m.Init())
+
+	if m.loading || m.refreshing || m.err != nil {
+		t.Fatalf("after load: loading=%v refreshing=%v err=%v", m.loading, m.refreshing, m.err)
+	}
+	if len(m.prs) != 3 || len(m.filtered) != 3 {
+		t.Fatalf("got %d PRs (%d shown), want 3", len(m.prs), len(m.filtered))
+	}
+	if !m.viewStates[m.view].refreshed {
+		t.Error("view not marked refreshed")
+	}
+}
+
+func TestUpdateLoadError(t *testing.T) {
+	m, fake := newTestModel(t, []PR{testPR(1, "One")})
+	fake.err = fmt.Errorf("boom")
+
+	m = run(t, m, m.Init())
+
+	if m.err == nil || m.loading {
+		t.Fatalf("err=%v loading=%v, want the error shown and loading done", m.err, m.loading)
+	}
+}
+
+func TestUpdateRefresh(t *testing.T) {
+	m, fake := newTestModel(t, []PR{testPR(1, "One"), testPR(2, "Two")})
+	m = run(t, m, m.Init())
+
+	// One PR closes, one gets a new title and another opens.
+	fake.mu.Lock()
+	fake.prs = []PR{testPR(2, "Two, retitled"), testPR(3, "Three")}
+	fake.mu.Unlock()
+
+	m = press(t, m, keysFor("refresh")[0])
+
+	if m.refreshing {
+		t.Fatal("still refreshing")
+	}
+	got := map[int]string{}
+	for _, pr := range m.prs {
+		got[pr.Number] = pr.Title
+	}
+	want := map[int]string{2: "Two, retitled", 3: "Three"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("after refresh got %v, want %v", got, want)
+	}
+}
+
+func TestUpdateApprove(t *testing.T) {
+	m, fake := newTestModel(t, []PR{testPR(7, "Seven")})
+	m = run(t, m, m.Init())
+
+	m = press(t, m, keysFor("approve")[0])
+	if m.confirmAction != "approve" || m.confirmPR == nil || m.confirmPR.Number != 7 {
+		t.Fatalf("approve prompt not showing: action=%q", m.confirmAction)
+	}
+
+	// y submits; run feeds the reviewSubmittedMsg back, which refreshes the PR.
+	m = press(t, m, "y")
+
+	if len(fake.reviews) != 1 || fake.reviews[0].Action != "approve" || fake.reviews[0].PR != "acme-corp/api#7" {
+		t.Fatalf("reviews submitted: %+v", fake.reviews)
+	}
+	if m.actionPending || m.actionStatus != "✓ Approved PR #7" {
+		t.Errorf("actionPending=%v actionStatus=%q", m.actionPending, m.actionStatus)
+	}
+	if m.prs[0].ReviewDecision != "APPROVED" {
+		t.Errorf("PR not refreshed after the review: decision %q", m.prs[0].ReviewDecision)
+	}
+}
+
+func TestUpdateReviewError(t *testing.T) {
+	m, _ := newTestModel(t, nil)
+	m.actionPending = true
+
+	next, cmd := m.Update(reviewSubmittedMsg{action: "approve", pr: testPR(7, "Seven"), err: fmt.Errorf("forbidden")})
+	m = next.(model)
+
+	if cmd != nil || m.actionPending || m.actionStatus != "Error: forbidden" {
+		t.Errorf("cmd=%v actionPending=%v actionStatus=%q", cmd != nil, m.actionPending, m.actionStatus)
+	}
+}
+
+func TestUpdateCheckoutLooksUpRepoInCmd(t *testing.T) {
+	m, _ := newTestModel(t, []PR{testPR(7, "Seven")})
+	m = run(t, m, m.Init())
+	t.Setenv("HOME", t.TempDir())
+	demoMode = false // demo mode checks out without looking
+	oldRepos := cfg.Repos
+	t.Cleanup(func() { cfg.Repos = oldRepos })
+	cfg.Repos = map[string]string{"acme-corp/api": "/src/api"}
+
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(model)
+	if m.quitting || !m.actionPending {
+		t.Fatalf("quitting=%v actionPending=%v, want the lookup in flight", m.quitting, m.actionPending)
+	}
+	m = run(t, m, cmd)
+	if !m.quitting || m.selected == nil || m.selected.Number != 7 {
+		t.Fatalf("quitting=%v selected=%v, want PR #7 checked out", m.quitting, m.selected)
+	}
+
+	// Not cloned anywhere: L offers to clone it.
+	m, _ = newTestModel(t, []PR{testPR(8, "Eight")})
+	m = run(t, m, m.Init())
+	cfg.Repos = nil
+	m = press(t, m, keysFor("clone")[0])
+	if m.actionPending || m.confirmAction != "clone" || m.confirmPR.Number != 8 {
+		t.Errorf("actionPending=%v confirmAction=%q, want the clone prompt", m.actionPending, m.confirmAction)
+	}
+
+	// Once cloned, the index update runs in a cmd before the checkout.
+	next, cmd = m.Update(cloneDoneMsg{pr: testPR(8, "Eight"), path: t.TempDir(), then: "checkout"})
+	m = next.(model)
+	if m.quitting || !m.actionPending {
+		t.Fatalf("quitting=%v actionPending=%v, want the clone being indexed", m.quitting, m.actionPending)
+	}
+	m = run(t, m, cmd)
+	if !m.quitting || m.selected == nil || m.selected.Number != 8 {
+		t.Errorf("quitting=%v selected=%v, want PR #8 checked out", m.quitting, m.selected)
+	}
+}
+
+func TestUpdateRefreshKeepsFailedHostsPRs(t *testing.T) {
+	m, dotcom := newTestModel(t, []PR{testPR(1, "On github.com"), testPR(3, "Also on github.com")})
+	dotcom.pageSize = 1 // so github.com's shard finishes last, after GHE's
+	ghePR := testPR(2, "On GHE")
+	ghePR.Host = "ghe.acme.com"
+	ghePR.Repository.Owner.Login = "infra"
+	ghe := newFakeBackend([]PR{ghePR})
+	newBackend = func(host string) Backend {
+		if host == "ghe.acme.com" {
+			return ghe
+		}
+		return dotcom
+	}
+	oldHosts := opts.Hosts
+	t.Cleanup(func() { opts.Hosts = oldHosts })
+	opts.Hosts = "infra=ghe.acme.com"
+	orgs = []string{"acme-corp", "infra"}
+
+	m = run(t, m, m.Init())
+	if len(m.prs) != 3 {
+		t.Fatalf("got %d PRs after the first load, want 3", len(m.prs))
+	}
+
+	// GHE is down for the next refresh; its PR mustn't be pruned.
+	ghe.mu.Lock()
+	ghe.err = fmt.Errorf("502 Bad Gateway")
+	ghe.mu.Unlock()
+	m = press(t, m, keysFor("refresh")[0])
+
+	if m.refreshing || m.refreshErrs == 0 {
+		t.Fatalf("refreshing=%v refreshErrs=%d, want a finished refresh with the failure counted", m.refreshing, m.refreshErrs)
+	}
+	if len(m.prs) != 3 {
+		t.Errorf("got %d PRs after GHE failed, want all 3 kept", len(m.prs))
+	}
+}
+
+func TestUpdateViewFilterStays(t *testing.T) {
+	draft := testPR(2, "Two")
+	draft.IsDraft = true
+	m, _ := newTestModel(t, []PR{testPR(1, "One"), draft, testPR(3, "Three")})
+	drafts, _ := parseFilter("is:draft")
+	views = append(views, viewConfig{Name: "Drafts", Filter: "is:draft", filter: drafts})
+	m = initialModel()
+	m = run(t, m, m.Init())
+	m = press(t, m, keysFor("view-2")[0])
+
+	shown := func() []int {
+		var n []int
+		for _, pr := range m.filtered {
+			n = append(n, pr.Number)
+		}
+		return n
+	}
+	if m.filterText != "" || fmt.Sprint(shown()) != "[2]" {
+		t.Fatalf("filterText=%q shown=%v, want only the draft with nothing typed", m.filterText, shown())
+	}
+
+	// Neither the toggles nor esc clear the view's filter.
+	m = press(t, m, keysFor("toggle-review")[0])
+	m = press(t, m, keysFor("toggle-review")[0])
+	m = press(t, m, keysFor("filter")[0])
+	m = press(t, m, "T")
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = run(t, next.(model), cmd)
+	if m.filterText != "" || fmt.Sprint(shown()) != "[2]" {
+		t.Errorf("after toggling and esc: filterText=%q shown=%v, want [2]", m.filterText, shown())
+	}
+}
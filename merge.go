@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// mergeInfo is what the merge prompt needs to know before offering choices:
+// which strategies the repo allows and whether the PR can merge right now.
+type mergeInfo struct {
+	PullRequestID string
+	HeadRefID     string // "" for fork PRs, whose branch we can't delete
+	// Methods lists the allowed strategies in GitHub's enum spelling
+	// (MERGE, SQUASH, REBASE), in the order the prompt shows them.
+	Methods                  []string
+	Mergeable                string // MERGEABLE, CONFLICTING, UNKNOWN
+	MergeStateStatus         string // CLEAN, BLOCKED, BEHIND, UNSTABLE, DIRTY, HAS_HOOKS...
+	AutoMergeAllowed         bool
+	ViewerCanEnableAutoMerge bool
+	ViewerCanMergeAsAdmin    bool
+	DeleteBranchOnMerge      bool // repo setting; GitHub deletes the branch itself
+}
+
+type mergeOptions struct {
+	Method       string // MERGE, SQUASH or REBASE
+	Auto         bool   // enable auto-merge instead of merging now
+	DeleteBranch bool
+}
+
+type mergeInfoMsg struct {
+	pr   PR
+	info mergeInfo
+	err  error
+}
+
+// mergeKeys maps prompt keys to merge methods.
+var mergeKeys = []struct {
+	key, method, label string
+}{
+	{"m", "MERGE", "merge"},
+	{"s", "SQUASH", "squash"},
+	{"r", "REBASE", "rebase"},
+}
+
+func fetchMergeInfoCmd(pr PR) tea.Cmd {
+	return func() tea.Msg {
+		info, err := backendFor(prHost(pr)).MergeInfo(pr)
+		return mergeInfoMsg{pr: pr, info: info, err: err}
+	}
+}
+
+func mergePRCmd(pr PR, opts mergeOptions) tea.Cmd {
+	action := "merge"
+	if opts.Auto {
+		action = "auto-merge"
+	}
+	return func() tea.Msg {
+		if err := backendFor(prHost(pr)).MergePR(pr, opts); err != nil {
+			return reviewSubmittedMsg{action: action, pr: pr, err: err}
+		}
+		return reviewSubmittedMsg{action: action, pr: pr}
+	}
+}
+
+// mergeWouldWait reports whether merging now would be refused because
+// required checks or reviews are still outstanding — the case where
+// auto-merge is the useful default.
+func mergeWouldWait(pr PR, info mergeInfo) bool {
+	if ciState(pr) == "pending" {
+		return true
+	}
+	return info.MergeStateStatus == "BLOCKED" || info.MergeStateStatus == "UNSTABLE"
+}
+
+func (info mergeInfo) allows(method string) bool {
+	for _, m := range info.Methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// startMergePrompt turns a mergeInfoMsg into the strategy prompt, or an
+// error in the status line when the PR can't be merged at all.
+func (m *model) startMergePrompt(msg mergeInfoMsg) {
+	m.actionPending = false
+	m.pendingLabel = ""
+	if msg.err != nil {
+		m.actionStatus = "Error: " + msg.err.Error()
+		return
+	}
+	info := msg.info
+	switch {
+	case info.Mergeable == "CONFLICTING":
+		m.actionStatus = fmt.Sprintf("Error: PR #%d has merge conflicts", msg.pr.Number)
+		return
+	case len(info.Methods) == 0:
+		m.actionStatus = fmt.Sprintf("Error: %s/%s allows no merge methods", msg.pr.Repository.Owner.Login, msg.pr.Repository.Name)
+		return
+	}
+	pr := msg.pr
+	m.confirmAction = "merge"
+	m.confirmPR = &pr
+	m.mergeInfo = &info
+	m.mergeAuto = mergeWouldWait(pr, info) && info.AutoMergeAllowed && info.ViewerCanEnableAutoMerge
+	m.mergeDelete = info.HeadRefID != "" && !info.DeleteBranchOnMerge
+}
+
+// handleMergeKey handles input while the merge prompt is showing.
+func (m model) handleMergeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	info := m.mergeInfo
+	switch key := msg.String(); key {
+	case "a":
+		if info.AutoMergeAllowed && info.ViewerCanEnableAutoMerge {
+			m.mergeAuto = !m.mergeAuto
+		}
+		return m, nil
+	case "d":
+		if info.HeadRefID != "" {
+			m.mergeDelete = !m.mergeDelete
+		}
+		return m, nil
+	default:
+		for _, mk := range mergeKeys {
+			if key != mk.key || !info.allows(mk.method) {
+				continue
+			}
+			pr := *m.confirmPR
+			opts := mergeOptions{Method: mk.method, Auto: m.mergeAuto, DeleteBranch: m.mergeDelete}
+			m.clearMergePrompt()
+			m.actionPending = true
+			m.pendingLabel = "Merging..."
+			if opts.Auto {
+				m.pendingLabel = "Enabling auto-merge..."
+			}
+			m.actionStatus = ""
+			return m, tea.Batch(mergePRCmd(pr, opts), spinnerTick())
+		}
+	}
+	// Anything else cancels.
+	m.clearMergePrompt()
+	return m, nil
+}
+
+func (m *model) clearMergePrompt() {
+	m.confirmAction = ""
+	m.confirmPR = nil
+	m.mergeInfo = nil
+	m.mergeAuto = false
+	m.mergeDelete = false
+}
+
+func (m model) mergePromptView() string {
+	info := m.mergeInfo
+	var opts []string
+	for _, mk := range mergeKeys {
+		if info.allows(mk.method) {
+			opts = append(opts, "["+mk.key+"]"+mk.label[1:])
+		}
+	}
+	verb := "Merge"
+	if m.mergeAuto {
+		verb = "Auto-merge"
+	}
+	prompt := fmt.Sprintf("  %s PR #%d: %s", verb, m.confirmPR.Number, strings.Join(opts, " "))
+	if info.AutoMergeAllowed && info.ViewerCanEnableAutoMerge {
+		prompt += " · [a]uto " + onOff(m.mergeAuto)
+	}
+	if info.HeadRefID != "" {
+		prompt += " · [d]elete branch " + onOff(m.mergeDelete)
+	} else if info.DeleteBranchOnMerge {
+		prompt += " · branch auto-deleted"
+	}
+	if !m.mergeAuto && mergeWouldWait(*m.confirmPR, *info) {
+		if info.ViewerCanMergeAsAdmin {
+			prompt += " · checks pending (admin override)"
+		} else {
+			prompt += " · checks pending"
+		}
+	}
+	return prompt + " · esc cancel"
+}
+
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
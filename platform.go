@@ -0,0 +1,122 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/aymanbagabas/go-osc52/v2"
+)
+
+// Opening URLs and copying to the clipboard differ per platform. Both try
+// the user's explicit choice first, then what the OS normally provides.
+
+// openURL opens url in a browser without waiting for it.
+func openURL(url string) error {
+	name, args, err := openCommand()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(name, append(args, url)...)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// openCommand picks the URL opener: $BROWSER, then open on macOS, wslview
+// under WSL, and xdg-open elsewhere.
+func openCommand() (string, []string, error) {
+	if b := strings.Fields(os.Getenv("BROWSER")); len(b) > 0 {
+		return b[0], b[1:], nil
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		return "open", []string{"-g"}, nil // -g: don't steal focus from the terminal
+	case "windows":
+		return "rundll32", []string{"url.dll,FileProtocolHandler"}, nil
+	}
+	if isWSL() {
+		if _, err := exec.LookPath("wslview"); err == nil {
+			return "wslview", nil, nil
+		}
+	}
+	if _, err := exec.LookPath("xdg-open"); err == nil {
+		return "xdg-open", nil, nil
+	}
+	return "", nil, errors.New("no way to open URLs: install xdg-utils (or wslu under WSL) or set $BROWSER")
+}
+
+func isWSL() bool {
+	if os.Getenv("WSL_DISTRO_NAME") != "" {
+		return true
+	}
+	data, err := os.ReadFile("/proc/sys/kernel/osrelease")
+	return err == nil && strings.Contains(strings.ToLower(string(data)), "microsoft")
+}
+
+// clipboardTools are tried in order; a tool is skipped when the display
+// server it needs isn't there.
+var clipboardTools = []struct {
+	name string
+	args []string
+	env  string // must be set for the tool to work, if any
+}{
+	{name: "wl-copy", env: "WAYLAND_DISPLAY"},
+	{name: "xclip", args: []string{"-selection", "clipboard"}, env: "DISPLAY"},
+	{name: "xsel", args: []string{"--clipboard", "--input"}, env: "DISPLAY"},
+	{name: "pbcopy"},
+	{name: "clip.exe"},
+}
+
+// copyToClipboard copies text and reports how. Over SSH it goes straight to
+// OSC 52, which asks the local terminal to set its clipboard; otherwise OSC
+// 52 is the fallback when no clipboard tool is installed.
+func copyToClipboard(text string) (string, error) {
+	if !isSSH() {
+		for _, t := range clipboardTools {
+			if t.env != "" && os.Getenv(t.env) == "" {
+				continue
+			}
+			if _, err := exec.LookPath(t.name); err != nil {
+				continue
+			}
+			cmd := exec.Command(t.name, t.args...)
+			cmd.Stdin = strings.NewReader(text)
+			if out, err := cmd.CombinedOutput(); err != nil {
+				if msg := strings.TrimSpace(string(out)); msg != "" {
+					return "", fmt.Errorf("%s: %s", t.name, msg)
+				}
+				return "", fmt.Errorf("%s: %w", t.name, err)
+			}
+			return t.name, nil
+		}
+	}
+	if err := copyOSC52(text); err != nil {
+		return "", err
+	}
+	return "OSC 52", nil
+}
+
+func isSSH() bool {
+	return os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != ""
+}
+
+// copyOSC52 writes the OSC 52 sequence to the terminal, wrapped for tmux or
+// screen when running inside one. Terminals that don't support it ignore
+// it silently.
+func copyOSC52(text string) error {
+	seq := osc52.New(text)
+	switch {
+	case os.Getenv("TMUX") != "":
+		seq = seq.Tmux()
+	case strings.HasPrefix(os.Getenv("TERM"), "screen"):
+		seq = seq.Screen()
+	}
+	_, err := seq.WriteTo(os.Stderr)
+	return err
+}
@@ -0,0 +1,417 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Worktrees left behind by merged and closed PRs are found here, for
+// `sup worktrees` and the W screen, and pruned along with their branches.
+
+// prWorktree is a linked worktree and the PR its branch was opened from.
+type prWorktree struct {
+	worktree
+	Repo    localRepo
+	PR      *PR   // nil when no PR was opened from the branch, or the lookup failed
+	TipInPR bool  // the branch is at the PR's head or behind it, so it has nothing the PR lacks
+	Changes int   // uncommitted changes, untracked files included
+	Err     error // the PR lookup failed, so the state is unknown
+}
+
+// state is the worktree's PR state as shown in the list.
+func (w prWorktree) state() string {
+	switch {
+	case w.Prunable:
+		return "missing"
+	case w.Branch == "":
+		return "detached"
+	case w.Err != nil:
+		return "unknown"
+	case w.PR == nil:
+		return "no PR"
+	case w.PR.State == "MERGED":
+		return "merged"
+	case w.PR.State == "CLOSED":
+		return "closed"
+	}
+	return "open"
+}
+
+// stale reports whether the worktree has outlived its PR: merged, closed,
+// or its directory already deleted.
+func (w prWorktree) stale() bool {
+	switch w.state() {
+	case "merged", "closed", "missing":
+		return true
+	}
+	return false
+}
+
+// removable reports whether pruning may remove the worktree: never when
+// locked, and with uncommitted changes only when forced.
+func (w prWorktree) removable(force bool) bool {
+	return !w.Locked && (w.Changes == 0 || force)
+}
+
+// label is the worktree's PR and branch, e.g. "acme/api#142 feature/x".
+func (w prWorktree) label() string {
+	ref := w.Repo.slug()
+	if w.PR != nil {
+		ref = fmt.Sprintf("%s#%d", ref, w.PR.Number)
+	}
+	if w.Branch == "" {
+		return ref + " (detached)"
+	}
+	return ref + " " + w.Branch
+}
+
+// notes are the warnings shown after a worktree in the list.
+func (w prWorktree) notes() string {
+	var notes []string
+	if w.Changes > 0 {
+		notes = append(notes, fmt.Sprintf("%d uncommitted change(s)", w.Changes))
+	}
+	if w.Locked {
+		notes = append(notes, "locked")
+	}
+	if w.Err != nil {
+		notes = append(notes, w.Err.Error())
+	}
+	return strings.Join(notes, ", ")
+}
+
+// collectWorktrees lists the linked worktrees of every discovered clone and
+// looks up their PRs, one query per repo.
+func collectWorktrees() []prWorktree {
+	repos := discoverRepos()
+	found := make([][]prWorktree, len(repos))
+	var wg sync.WaitGroup
+	for i, repo := range repos {
+		wg.Add(1)
+		go func(i int, repo localRepo) {
+			defer wg.Done()
+			found[i] = repoWorktrees(repo)
+		}(i, repo)
+	}
+	wg.Wait()
+	var all []prWorktree
+	for _, wts := range found {
+		all = append(all, wts...)
+	}
+	return all
+}
+
+func repoWorktrees(repo localRepo) []prWorktree {
+	wts, err := listWorktrees(repo.Path)
+	if err != nil || len(wts) < 2 {
+		return nil
+	}
+	var out []prWorktree
+	var branches []string
+	for _, wt := range wts[1:] { // the first is the main clone
+		if wt.Bare {
+			continue
+		}
+		w := prWorktree{worktree: wt, Repo: repo}
+		if !wt.Prunable {
+			changes, err := uncommittedChanges(wt.Path, true)
+			if err != nil {
+				w.Err = err
+			}
+			w.Changes = len(changes)
+		}
+		if wt.Branch != "" {
+			branches = append(branches, wt.Branch)
+		}
+		out = append(out, w)
+	}
+	if len(branches) == 0 {
+		return out
+	}
+	prs, err := backendFor(repo.Host).BranchPRs(repo.Owner, repo.Name, branches)
+	remotes := readRemotes(repo.Path)
+	for i := range out {
+		if err != nil {
+			out[i].Err = err
+		} else if pr, ok := prs[out[i].Branch]; ok && prFromBranch(repo.Path, remotes, out[i].Branch, pr) {
+			out[i].PR = &pr
+			out[i].TipInPR = tipInPR(repo.Path, out[i].Head, pr.HeadRefOid)
+		}
+	}
+	return out
+}
+
+// prFromBranch reports whether pr was opened from the clone's branch rather
+// than from a branch elsewhere with the same name, such as someone's fork's
+// main: the PR's head repo has to be one of the clone's remotes, or the
+// remote the branch tracks (gh pr checkout tracks forks by URL).
+func prFromBranch(repoPath string, remotes []gitRemote, branch string, pr PR) bool {
+	if pr.HeadRepository == nil {
+		return false
+	}
+	head := pr.HeadRepository.Owner.Login + "/" + pr.HeadRepository.Name
+	for _, r := range remotes {
+		if strings.EqualFold(r.Owner+"/"+r.Repo, head) {
+			return true
+		}
+	}
+	out, err := runGit(repoPath, "config", "branch."+branch+".remote")
+	if err != nil {
+		return false
+	}
+	_, owner, name, ok := parseRemoteURL(string(out))
+	return ok && strings.EqualFold(owner+"/"+name, head)
+}
+
+// tipInPR reports whether a branch at tip is at the PR's head commit or an
+// ancestor of it. A head that was never fetched counts as not.
+func tipInPR(repoPath, tip, headOid string) bool {
+	if tip == "" || headOid == "" {
+		return false
+	}
+	if tip == headOid {
+		return true
+	}
+	_, err := runGit(repoPath, "merge-base", "--is-ancestor", tip, headOid)
+	return err == nil
+}
+
+// branchDeleteFlag is how pruning deletes the worktree's branch. -D is
+// needed as squash and rebase merges leave the branch looking unmerged, but
+// only once the PR has every commit on it; otherwise -d, which refuses to
+// delete work git can't see merged.
+func (w prWorktree) branchDeleteFlag() string {
+	if w.TipInPR {
+		return "-D"
+	}
+	return "-d"
+}
+
+// pruneWorktree removes a worktree and deletes its local branch. A worktree
+// whose directory is already gone needs --force to drop git's record of it;
+// git worktree prune would drop every such record in the repo, not just
+// this one. kept says why the branch stayed when it has commits the PR
+// doesn't.
+func pruneWorktree(w prWorktree, force bool) (kept string, err error) {
+	if w.Locked {
+		return "", fmt.Errorf("%s is locked (git worktree unlock it first)", w.Path)
+	}
+	args := []string{"worktree", "remove", w.Path}
+	if force || w.Prunable {
+		args = append(args, "--force")
+	}
+	if _, err := runGit(w.Repo.Path, args...); err != nil {
+		return "", err
+	}
+	if w.Branch == "" {
+		return "", nil
+	}
+	if _, err := runGit(w.Repo.Path, "branch", w.branchDeleteFlag(), w.Branch); err != nil {
+		if w.TipInPR {
+			return "", err
+		}
+		return fmt.Sprintf("kept branch %s: it has commits that aren't in the PR", w.Branch), nil
+	}
+	return "", nil
+}
+
+// shortPath abbreviates $HOME to ~ for display.
+func shortPath(p string) string {
+	home := os.Getenv("HOME")
+	if rel, err := filepath.Rel(home, p); home != "" && err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.Join("~", rel)
+	}
+	return p
+}
+
+// worktreesScreen is the W screen's state.
+type worktreesScreen struct {
+	list    []prWorktree
+	loading bool
+	pruning bool
+	cursor  int
+	marked  map[string]bool // paths to prune
+	confirm bool            // showing the dry run; y prunes
+	status  string
+}
+
+type worktreesLoadedMsg struct {
+	list []prWorktree
+}
+
+type worktreesPrunedMsg struct {
+	removed int
+	kept    []string // why branches were kept
+	errs    []error
+}
+
+func loadWorktreesCmd() tea.Cmd {
+	return func() tea.Msg {
+		return worktreesLoadedMsg{list: collectWorktrees()}
+	}
+}
+
+func pruneWorktreesCmd(wts []prWorktree) tea.Cmd {
+	return func() tea.Msg {
+		var msg worktreesPrunedMsg
+		for _, w := range wts {
+			kept, err := pruneWorktree(w, false)
+			if err != nil {
+				msg.errs = append(msg.errs, err)
+				continue
+			}
+			msg.removed++
+			if kept != "" {
+				msg.kept = append(msg.kept, kept)
+			}
+		}
+		return msg
+	}
+}
+
+func (s *worktreesScreen) busy() bool {
+	return s != nil && (s.loading || s.pruning)
+}
+
+func (s *worktreesScreen) setList(list []prWorktree) {
+	s.list = list
+	s.loading = false
+	s.cursor = min(s.cursor, max(len(list)-1, 0))
+	s.marked = map[string]bool{}
+	for _, w := range list {
+		if w.stale() && w.removable(false) {
+			s.marked[w.Path] = true
+		}
+	}
+}
+
+func (s *worktreesScreen) markedList() []prWorktree {
+	var out []prWorktree
+	for _, w := range s.list {
+		if s.marked[w.Path] {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// handleWorktreesKey handles input while the W screen is showing.
+func (m model) handleWorktreesKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	s := m.worktrees
+	key := msg.String()
+	if s.loading || s.pruning {
+		if closesOverlay(key, "worktrees") {
+			m.worktrees = nil
+		}
+		return m, nil
+	}
+	if s.confirm {
+		s.confirm = false
+		if key == "y" || key == "Y" {
+			s.pruning = true
+			s.status = ""
+			return m, tea.Batch(pruneWorktreesCmd(s.markedList()), spinnerTick())
+		}
+		return m, nil
+	}
+	s.status = ""
+	switch act := actionFor(key); {
+	case key == " ":
+		if len(s.list) == 0 {
+			break
+		}
+		w := s.list[s.cursor]
+		switch {
+		case s.marked[w.Path]:
+			delete(s.marked, w.Path)
+		case !w.removable(false):
+			s.status = "Has uncommitted changes or is locked; use sup worktrees --prune --force " + shortPath(w.Path)
+		default:
+			s.marked[w.Path] = true
+		}
+	case key == "x":
+		if len(s.markedList()) == 0 {
+			s.status = "Nothing marked; space marks a worktree"
+		} else {
+			s.confirm = true
+		}
+	case act == "down":
+		s.cursor = min(s.cursor+1, max(len(s.list)-1, 0))
+	case act == "up":
+		s.cursor = max(s.cursor-1, 0)
+	case act == "refresh":
+		s.loading = true
+		return m, tea.Batch(loadWorktreesCmd(), spinnerTick())
+	case closesOverlay(key, "worktrees"):
+		m.worktrees = nil
+	}
+	return m, nil
+}
+
+func (m model) worktreesView() string {
+	s := m.worktrees
+	var b strings.Builder
+	b.WriteString("\n  " + titleStyle.Render("Worktrees") + "\n\n")
+	switch {
+	case s.loading:
+		b.WriteString("  " + loadingStyle.Render(spinnerFrames[m.spinnerFrame]+" Looking up worktrees and their PRs...") + "\n")
+	case len(s.list) == 0:
+		b.WriteString("  No linked worktrees in your repos.\n")
+	}
+	if !s.loading {
+		for i, w := range s.list {
+			cursor, mark := "  ", "[ ] "
+			if i == s.cursor {
+				cursor = caretStyle.Render("> ")
+			}
+			if s.marked[w.Path] {
+				mark = "[x] "
+			}
+			line := fmt.Sprintf("%-9s %s", w.state(), w.label())
+			style := normalStyle
+			switch {
+			case w.stale():
+				style = approvedStyle
+			case w.state() == "unknown":
+				style = changesRequestedStyle
+			}
+			b.WriteString(cursor + mark + style.Render(truncate(line, m.width-8)) + "\n")
+			detail := shortPath(w.Path)
+			if notes := w.notes(); notes != "" {
+				detail += " · " + notes
+			}
+			b.WriteString("        " + dimStyle.Render(truncate(detail, m.width-10)) + "\n")
+		}
+	}
+
+	b.WriteString("\n")
+	switch {
+	case s.confirm:
+		marked := s.markedList()
+		b.WriteString("  " + filterStyle.Render(fmt.Sprintf("Remove %d worktree(s) and their local branches?", len(marked))) + "\n")
+		for _, w := range marked {
+			line := "git worktree remove " + shortPath(w.Path)
+			if w.Prunable {
+				line = "git worktree remove --force " + shortPath(w.Path) + " (its directory is gone)"
+			}
+			if w.Branch != "" {
+				line += " && git branch " + w.branchDeleteFlag() + " " + w.Branch
+			}
+			b.WriteString("    " + dimStyle.Render(truncate(line, m.width-6)) + "\n")
+		}
+		b.WriteString("  " + filterStyle.Render("y to prune, any other key to go back") + "\n")
+	case s.pruning:
+		b.WriteString("  " + loadingStyle.Render(spinnerFrames[m.spinnerFrame]+" Pruning...") + "\n")
+	default:
+		if s.status != "" {
+			b.WriteString("  " + s.status + "\n")
+		}
+		b.WriteString("  " + dimStyle.Render("space mark · x prune marked · "+keysLabel(keysFor("refresh"))+" reload · "+closeHint("worktrees")) + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func git(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.email=sup@example.com", "-c", "user.name=sup"}, args...)...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestPruneKeepsUnmergedWork(t *testing.T) {
+	root := t.TempDir()
+	clone := filepath.Join(root, "api")
+	os.MkdirAll(clone, 0755)
+	git(t, clone, "init", "-q", "-b", "main")
+	git(t, clone, "remote", "add", "origin", "git@github.com:acme/api.git")
+	git(t, clone, "commit", "-q", "--allow-empty", "-m", "initial")
+	heads := map[string]string{}
+	for _, b := range []string{"pushed", "ahead", "fix"} {
+		git(t, clone, "worktree", "add", "-q", "-b", b, filepath.Join(root, b))
+		git(t, filepath.Join(root, b), "commit", "-q", "--allow-empty", "-m", b)
+		heads[b] = git(t, clone, "rev-parse", b)
+	}
+	// Committed locally after the PR merged.
+	git(t, filepath.Join(root, "ahead"), "commit", "-q", "--allow-empty", "-m", "more")
+
+	merged := func(n int, branch, headOwner string) PR {
+		pr := testPR(n, branch)
+		pr.Repository.Owner.Login = "acme"
+		pr.HeadRefName, pr.HeadRefOid, pr.State = branch, heads[branch], "MERGED"
+		if headOwner != "" {
+			head := pr.Repository
+			head.Owner.Login = headOwner
+			pr.HeadRepository = &head
+		}
+		return pr
+	}
+	newTestModel(t, []PR{
+		merged(1, "pushed", ""),
+		merged(2, "ahead", ""),
+		merged(3, "fix", "alice"), // alice's fork, not this clone's fix
+	})
+
+	got := map[string]string{}
+	for _, w := range repoWorktrees(localRepo{Path: clone, Host: githubDotCom, Owner: "acme", Name: "api"}) {
+		got[w.Branch] = w.state() + " " + w.branchDeleteFlag()
+		if !w.stale() {
+			continue
+		}
+		kept, err := pruneWorktree(w, false)
+		if err != nil {
+			t.Fatalf("pruning %s: %v", w.Branch, err)
+		}
+		if (kept != "") != (w.Branch == "ahead") {
+			t.Errorf("pruning %s: kept %q", w.Branch, kept)
+		}
+	}
+	want := map[string]string{"pushed": "merged -D", "ahead": "merged -d", "fix": "no PR -d"}
+	for b, s := range want {
+		if got[b] != s {
+			t.Errorf("%s: got %q, want %q", b, got[b], s)
+		}
+	}
+	if branches := git(t, clone, "branch", "--format=%(refname:short)"); branches != "ahead\nfix\nmain" {
+		t.Errorf("branches left: %q", branches)
+	}
+}
+
+func TestPruneGoneWorktreeOnly(t *testing.T) {
+	root := t.TempDir()
+	clone := filepath.Join(root, "api")
+	os.MkdirAll(clone, 0755)
+	git(t, clone, "init", "-q", "-b", "main")
+	git(t, clone, "commit", "-q", "--allow-empty", "-m", "initial")
+	for _, b := range []string{"one", "two"} {
+		git(t, clone, "worktree", "add", "-q", "-b", b, filepath.Join(root, b))
+		os.RemoveAll(filepath.Join(root, b))
+	}
+	newTestModel(t, nil)
+
+	pruned := false
+	for _, w := range repoWorktrees(localRepo{Path: clone, Host: githubDotCom, Owner: "acme", Name: "api"}) {
+		if w.Branch != "one" {
+			continue
+		}
+		pruned = true
+		if !w.Prunable {
+			t.Fatalf("%s isn't prunable", w.Path)
+		}
+		if _, err := pruneWorktree(w, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !pruned {
+		t.Fatal("worktree one not listed")
+	}
+	// two's record is another worktree's business.
+	if list := git(t, clone, "worktree", "list"); strings.Contains(list, "/one ") || !strings.Contains(list, "/two ") {
+		t.Errorf("worktrees left:\n%s", list)
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// maxConcurrentRequests caps in-flight GraphQL requests across all hosts.
+	// A refresh fans out one command per shard and per cached-PR chunk; without
+	// a cap a few hundred cached PRs trips GitHub's secondary rate limits.
+	maxConcurrentRequests = 6
+	maxRetries            = 4
+	// maxRetryWait is the longest sup will sleep before retrying a request.
+	// Anything longer fails fast and leaves the footer showing the reset time.
+	maxRetryWait = 2 * time.Minute
+)
+
+var apiSlots = make(chan struct{}, maxConcurrentRequests)
+
+// rateLimitState tracks what GitHub last told us about one host's budget,
+// from both X-RateLimit-* headers and the rateLimit field in query results.
+type rateLimitState struct {
+	mu             sync.Mutex
+	remaining      int // -1 until the first response
+	limit          int
+	resetAt        time.Time
+	lastCost       int
+	throttledUntil time.Time
+}
+
+var (
+	rateLimitsMu sync.Mutex
+	rateLimits   = map[string]*rateLimitState{}
+)
+
+func rateLimitFor(host string) *rateLimitState {
+	rateLimitsMu.Lock()
+	defer rateLimitsMu.Unlock()
+	r, ok := rateLimits[host]
+	if !ok {
+		r = &rateLimitState{remaining: -1}
+		rateLimits[host] = r
+	}
+	return r
+}
+
+func (r *rateLimitState) observeHeaders(h http.Header) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if v, err := strconv.Atoi(h.Get("X-RateLimit-Remaining")); err == nil {
+		r.remaining = v
+	}
+	if v, err := strconv.Atoi(h.Get("X-RateLimit-Limit")); err == nil {
+		r.limit = v
+	}
+	if v, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		r.resetAt = time.Unix(v, 0)
+	}
+}
+
+func (r *rateLimitState) observeQuery(remaining, cost int, resetAt time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.remaining = remaining
+	r.lastCost = cost
+	if !resetAt.IsZero() {
+		r.resetAt = resetAt
+	}
+}
+
+func (r *rateLimitState) throttle(until time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if until.After(r.throttledUntil) {
+		r.throttledUntil = until
+	}
+}
+
+// wait blocks while the host is throttled, or while the primary budget is too
+// low to cover another query of the last observed cost. It returns an error
+// instead of sleeping past maxRetryWait.
+func (r *rateLimitState) wait() error {
+	r.mu.Lock()
+	until := r.throttledUntil
+	if r.remaining >= 0 && r.remaining < max(r.lastCost, 1) && r.resetAt.After(until) {
+		until = r.resetAt
+	}
+	r.mu.Unlock()
+
+	d := time.Until(until)
+	if d <= 0 {
+		return nil
+	}
+	if d > maxRetryWait {
+		r.throttle(until)
+		return fmt.Errorf("rate limited until %s", until.Local().Format("15:04"))
+	}
+	time.Sleep(d)
+	return nil
+}
+
+// retryDelay decides whether a response is worth retrying and how long to
+// wait first. Secondary rate limits come back as 403 or 429, usually with
+// Retry-After; an exhausted primary budget says so in X-RateLimit-Remaining.
+func retryDelay(status int, h http.Header, body []byte, attempt int) (time.Duration, bool) {
+	switch {
+	case status == http.StatusForbidden || status == http.StatusTooManyRequests:
+		if secs, err := strconv.Atoi(h.Get("Retry-After")); err == nil {
+			return time.Duration(secs) * time.Second, true
+		}
+		if h.Get("X-RateLimit-Remaining") == "0" {
+			if reset, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+				return time.Until(time.Unix(reset, 0)) + time.Second, true
+			}
+		}
+		if bytes.Contains(bytes.ToLower(body), []byte("secondary rate limit")) {
+			// GitHub asks for at least a minute when it doesn't say.
+			return time.Minute << attempt, true
+		}
+		return 0, false
+	case gatewayError(status):
+		return time.Second << attempt, true
+	}
+	return 0, false
+}
+
+// rateLimitNotice summarises throttling across hosts for the footer, or ""
+// when nothing is worth mentioning.
+func rateLimitNotice() string {
+	rateLimitsMu.Lock()
+	hosts := make([]string, 0, len(rateLimits))
+	for h := range rateLimits {
+		hosts = append(hosts, h)
+	}
+	rateLimitsMu.Unlock()
+	sort.Strings(hosts)
+
+	now := time.Now()
+	for _, h := range hosts {
+		r := rateLimitFor(h)
+		r.mu.Lock()
+		throttled, remaining, limit, resetAt := r.throttledUntil, r.remaining, r.limit, r.resetAt
+		r.mu.Unlock()
+
+		label := ""
+		if len(hosts) > 1 {
+			label = h + " "
+		}
+		if throttled.After(now) {
+			return fmt.Sprintf("⏸ %sthrottled — resuming %s", label, throttled.Local().Format("15:04:05"))
+		}
+		if remaining == 0 && resetAt.After(now) {
+			return fmt.Sprintf("⏸ %srate limit exhausted — resets %s", label, resetAt.Local().Format("15:04"))
+		}
+		if limit > 0 && remaining >= 0 && remaining*10 < limit && resetAt.After(now) {
+			return fmt.Sprintf("%sAPI %d/%d left — resets %s", label, remaining, limit, resetAt.Local().Format("15:04"))
+		}
+	}
+	return ""
+}
+
+// gatewayError reports whether status is a transient gateway failure, after
+// which the request may or may not have been carried out.
+func gatewayError(status int) bool {
+	return status == http.StatusBadGateway || status == http.StatusServiceUnavailable || status == http.StatusGatewayTimeout
+}
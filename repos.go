@@ -0,0 +1,315 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The repo index maps GitHub repos to local clones. It's built by walking
+// the repo roots a few levels deep, so nested layouts like
+// ~/src/github.com/owner/repo are found, and it identifies each clone by
+// its remotes rather than its directory name, so two orgs' "api" repos and
+// forks (through their upstream remote) resolve to the right place. It's
+// cached in repos.json and rebuilt when the roots change, when a lookup
+// misses, or on `sup repos --refresh`.
+
+// defaultRepoDepth is how many levels below each root are searched.
+const defaultRepoDepth = 3
+
+// skipDirs are never searched for clones.
+var skipDirs = map[string]bool{
+	"node_modules": true, "vendor": true, "Library": true, "Applications": true,
+	"Downloads": true, "Movies": true, "Music": true, "Pictures": true,
+}
+
+// localRepo is a clone on disk and the GitHub repo it was cloned from.
+type localRepo struct {
+	Path  string
+	Host  string
+	Owner string
+	Name  string
+}
+
+func (r localRepo) slug() string {
+	return r.Owner + "/" + r.Name
+}
+
+// gitRemote is one remote of a clone, from `git remote -v`.
+type gitRemote struct {
+	Name  string `json:"name"`
+	Host  string `json:"host"`
+	Owner string `json:"owner"`
+	Repo  string `json:"repo"`
+}
+
+type indexedClone struct {
+	Path    string      `json:"path"`
+	Remotes []gitRemote `json:"remotes"`
+}
+
+// primary is the remote that names the clone: origin, else the first.
+func (c indexedClone) primary() (gitRemote, bool) {
+	for _, r := range c.Remotes {
+		if r.Name == "origin" {
+			return r, true
+		}
+	}
+	if len(c.Remotes) > 0 {
+		return c.Remotes[0], true
+	}
+	return gitRemote{}, false
+}
+
+type repoIndex struct {
+	Roots  []string       `json:"roots"`
+	Depth  int            `json:"depth"`
+	Built  time.Time      `json:"built"`
+	Clones []indexedClone `json:"clones"`
+}
+
+var (
+	repoIndexMu    sync.Mutex
+	repoIndexMem   *repoIndex
+	repoIndexBuilt bool // rebuilt during this run, so a miss is a real miss
+)
+
+func repoIndexPath() string { return filepath.Join(cacheDir(), "repos.json") }
+
+// indexRoots are the directories the index walks: SUP_DEV_DIR, then the
+// repo roots.
+func indexRoots() []string {
+	roots := repoRoots()
+	if opts.DevDir != "" {
+		roots = append([]string{expandPath(opts.DevDir)}, roots...)
+	}
+	return roots
+}
+
+func repoDepth() int {
+	if cfg.RepoDepth > 0 {
+		return cfg.RepoDepth
+	}
+	return defaultRepoDepth
+}
+
+// loadRepoIndex returns the cached index, building it if there's none or
+// it was built for other roots.
+func loadRepoIndex() *repoIndex {
+	repoIndexMu.Lock()
+	defer repoIndexMu.Unlock()
+	if repoIndexMem != nil {
+		return repoIndexMem
+	}
+	if data, err := os.ReadFile(repoIndexPath()); err == nil {
+		var idx repoIndex
+		if json.Unmarshal(data, &idx) == nil && slices.Equal(idx.Roots, indexRoots()) && idx.Depth == repoDepth() {
+			repoIndexMem = &idx
+			return repoIndexMem
+		}
+	}
+	return refreshRepoIndexLocked()
+}
+
+// refreshRepoIndex rebuilds the index and saves it.
+func refreshRepoIndex() *repoIndex {
+	repoIndexMu.Lock()
+	defer repoIndexMu.Unlock()
+	return refreshRepoIndexLocked()
+}
+
+func refreshRepoIndexLocked() *repoIndex {
+	idx := buildRepoIndex(indexRoots(), repoDepth())
+	repoIndexMem, repoIndexBuilt = idx, true
+	if data, err := json.Marshal(idx); err == nil {
+		os.WriteFile(repoIndexPath(), data, 0644)
+	}
+	return idx
+}
+
+// buildRepoIndex walks roots for clones and reads their remotes.
+func buildRepoIndex(roots []string, depth int) *repoIndex {
+	var paths []string
+	seen := map[string]bool{}
+	home := os.Getenv("HOME")
+	for _, root := range roots {
+		d := depth
+		if root == home {
+			d = 1 // $HOME itself only one level down, as it's huge
+		}
+		walkForClones(root, d, func(path string) {
+			if real, err := filepath.EvalSymlinks(path); err == nil {
+				path = real
+			}
+			if !seen[path] {
+				seen[path] = true
+				paths = append(paths, path)
+			}
+		})
+	}
+
+	clones := make([]indexedClone, len(paths))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, 8)
+	for i, path := range paths {
+		wg.Add(1)
+		go func(i int, path string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			clones[i] = indexedClone{Path: path, Remotes: readRemotes(path)}
+		}(i, path)
+	}
+	wg.Wait()
+	sort.Slice(clones, func(i, j int) bool { return clones[i].Path < clones[j].Path })
+	return &repoIndex{Roots: roots, Depth: depth, Built: time.Now(), Clones: clones}
+}
+
+// walkForClones calls found for each clone up to depth levels below dir,
+// without descending into clones or hidden directories. Symlinks are
+// followed to clones but not searched, so links can't loop.
+func walkForClones(dir string, depth int, found func(string)) {
+	if depth <= 0 {
+		return
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		name := e.Name()
+		link := e.Type()&os.ModeSymlink != 0
+		if (!e.IsDir() && !link) || strings.HasPrefix(name, ".") || skipDirs[name] {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		if isClone(path) {
+			found(path)
+		} else if !link {
+			walkForClones(path, depth-1, found)
+		}
+	}
+}
+
+// isClone reports whether path is a main clone. Linked worktrees have a
+// .git file rather than a directory.
+func isClone(path string) bool {
+	fi, err := os.Stat(filepath.Join(path, ".git"))
+	return err == nil && fi.IsDir()
+}
+
+// readRemotes lists a clone's GitHub remotes from `git remote -v`.
+func readRemotes(path string) []gitRemote {
+	cmd := exec.Command("git", "remote", "-v")
+	cmd.Dir = path
+	out, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+	var remotes []gitRemote
+	for _, line := range strings.Split(string(out), "\n") {
+		// origin	git@github.com:acme/api.git (fetch)
+		fields := strings.Fields(line)
+		if len(fields) != 3 || fields[2] != "(fetch)" {
+			continue
+		}
+		if host, owner, repo, ok := parseRemoteURL(fields[1]); ok {
+			remotes = append(remotes, gitRemote{Name: fields[0], Host: host, Owner: owner, Repo: repo})
+		}
+	}
+	return remotes
+}
+
+// find returns the clone of owner/name on host. A clone whose origin is the
+// repo beats one that only has it as another remote (a fork's upstream).
+// Remotes on other hosts sup knows never match, so same-named repos on
+// github.com and GHE stay apart; ones on hosts it doesn't know (perhaps ssh
+// aliases) match when nothing on host does.
+func (idx *repoIndex) find(host, owner, name string) string {
+	host = normalizeHost(host)
+	known := map[string]bool{githubDotCom: true}
+	for _, h := range knownHosts() {
+		known[h] = true
+	}
+	best, bestScore := "", 0
+	for _, c := range idx.Clones {
+		for _, r := range c.Remotes {
+			if !strings.EqualFold(r.Owner, owner) || !strings.EqualFold(r.Repo, name) {
+				continue
+			}
+			score := 1
+			if r.Host == host {
+				score = 3
+			} else if known[r.Host] {
+				continue
+			}
+			if r.Name == "origin" {
+				score++
+			}
+			if score > bestScore {
+				best, bestScore = c.Path, score
+			}
+		}
+	}
+	return best
+}
+
+// lookupRepo finds owner/name on host in the index, rebuilding it once if
+// the cached index doesn't have it or points at a clone that's gone.
+func lookupRepo(host, owner, name string) string {
+	if path := loadRepoIndex().find(host, owner, name); path != "" && isClone(path) {
+		return path
+	}
+	repoIndexMu.Lock()
+	built := repoIndexBuilt
+	repoIndexMu.Unlock()
+	if built {
+		return ""
+	}
+	return refreshRepoIndex().find(host, owner, name)
+}
+
+// remoteURLPattern matches the remote URL forms git and gh write:
+// git@host:owner/repo.git, ssh://git@host/owner/repo and
+// https://host/owner/repo.git.
+var remoteURLPattern = regexp.MustCompile(`^(?:[a-z+]+://)?(?:[^@/]+@)?([^:/]+)(?::\d+)?[:/]([^/]+)/([^/]+?)(?:\.git)?/?$`)
+
+// parseRemoteURL splits a git remote URL into host, owner and repo.
+func parseRemoteURL(url string) (host, owner, name string, ok bool) {
+	m := remoteURLPattern.FindStringSubmatch(strings.TrimSpace(url))
+	if m == nil {
+		return "", "", "", false
+	}
+	return normalizeHost(m[1]), m[2], m[3], true
+}
+
+// discoverRepos lists the clones sup knows about: the [repos] mappings and
+// the index, each named by its origin remote.
+func discoverRepos() []localRepo {
+	clones := loadRepoIndex().Clones
+	seen := map[string]bool{}
+	for _, c := range clones {
+		seen[c.Path] = true
+	}
+	for _, path := range cfg.Repos {
+		if path = expandPath(path); !seen[path] && isClone(path) {
+			seen[path] = true
+			clones = append(clones, indexedClone{Path: path, Remotes: readRemotes(path)})
+		}
+	}
+	var repos []localRepo
+	for _, c := range clones {
+		if r, ok := c.primary(); ok {
+			repos = append(repos, localRepo{Path: c.Path, Host: r.Host, Owner: r.Owner, Name: r.Repo})
+		}
+	}
+	sort.Slice(repos, func(i, j int) bool { return repos[i].Path < repos[j].Path })
+	return repos
+}
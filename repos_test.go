@@ -0,0 +1,35 @@
+package main
+
+import "testing"
+
+func TestRepoIndexFindMatchesHost(t *testing.T) {
+	oldHosts := opts.Hosts
+	t.Cleanup(func() { opts.Hosts = oldHosts })
+	opts.Hosts = "acme-infra=ghe.acme.com"
+
+	remote := func(name, host string) gitRemote {
+		return gitRemote{Name: name, Host: host, Owner: "acme", Repo: "api"}
+	}
+	idx := &repoIndex{Clones: []indexedClone{
+		{Path: "/src/ghe/api", Remotes: []gitRemote{remote("origin", "ghe.acme.com")}},
+		{Path: "/src/fork/api", Remotes: []gitRemote{{Name: "origin", Host: githubDotCom, Owner: "me", Repo: "api"}, remote("upstream", githubDotCom)}},
+		{Path: "/src/alias/api", Remotes: []gitRemote{remote("origin", "github-work")}},
+	}}
+
+	tests := []struct{ host, want string }{
+		{"ghe.acme.com", "/src/ghe/api"},
+		{githubDotCom, "/src/fork/api"}, // the upstream on the right host beats an alias
+		{"ghe.other.com", "/src/alias/api"},
+	}
+	for _, tt := range tests {
+		if got := idx.find(tt.host, "acme", "api"); got != tt.want {
+			t.Errorf("find(%s, acme/api) = %q, want %q", tt.host, got, tt.want)
+		}
+	}
+
+	// With only the GHE clone, a github.com PR has no clone.
+	idx.Clones = idx.Clones[:1]
+	if got := idx.find(githubDotCom, "acme", "api"); got != "" {
+		t.Errorf("github.com PR matched the GHE clone %q", got)
+	}
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// sortTerm is one key of a sort order, e.g. "-created".
+type sortTerm struct {
+	key  string
+	desc bool
+}
+
+// sortSpec is a multi-key sort order. A nil spec sorts by number.
+type sortSpec []sortTerm
+
+// sortKeys are the names a sort order can use. Each compares ascending.
+var sortKeys = map[string]func(a, b PR) int{
+	"number":  func(a, b PR) int { return cmp.Compare(a.Number, b.Number) },
+	"created": func(a, b PR) int { return a.CreatedAt.Compare(b.CreatedAt) },
+	"updated": func(a, b PR) int { return a.UpdatedAt.Compare(b.UpdatedAt) },
+	"size":    func(a, b PR) int { return cmp.Compare(a.Additions+a.Deletions, b.Additions+b.Deletions) },
+	"repo": func(a, b PR) int {
+		return cmp.Compare(strings.ToLower(a.Repository.Owner.Login+"/"+a.Repository.Name), strings.ToLower(b.Repository.Owner.Login+"/"+b.Repository.Name))
+	},
+	"author": func(a, b PR) int {
+		return cmp.Compare(strings.ToLower(a.Author.Login), strings.ToLower(b.Author.Login))
+	},
+	"review": func(a, b PR) int { return cmp.Compare(reviewRank(a), reviewRank(b)) },
+	"ci":     func(a, b PR) int { return cmp.Compare(ciRank(a), ciRank(b)) },
+}
+
+// reviewRank orders statuses by how much they need a reviewer: PRs waiting
+// on review first, drafts last.
+func reviewRank(pr PR) int {
+	switch statusLabelForFilter(pr) {
+	case "review":
+		return 0
+	case "commented":
+		return 1
+	case "denied":
+		return 2
+	case "approved":
+		return 3
+	case "open":
+		return 4
+	}
+	return 5
+}
+
+// ciRank puts failing checks first and PRs without checks last.
+func ciRank(pr PR) int {
+	switch ciState(pr) {
+	case "failing":
+		return 0
+	case "pending":
+		return 1
+	case "passing":
+		return 2
+	}
+	return 3
+}
+
+// sortCycle is the order the sort key steps through, each with the
+// direction it's most useful in: newest and biggest first.
+var sortCycle = []sortTerm{
+	{key: "number"},
+	{key: "updated", desc: true},
+	{key: "created", desc: true},
+	{key: "size", desc: true},
+	{key: "repo"},
+	{key: "author"},
+	{key: "review"},
+	{key: "ci"},
+}
+
+// parseSort parses comma-separated keys, each descending when prefixed
+// with - or suffixed with -desc (GitHub's spelling): "-updated,repo",
+// "updated-desc".
+func parseSort(s string) (sortSpec, error) {
+	var spec sortSpec
+	for _, part := range strings.Split(s, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part == "" {
+			continue
+		}
+		key, desc := strings.CutPrefix(part, "-")
+		if k, ok := strings.CutSuffix(key, "-desc"); ok {
+			key, desc = k, true
+		} else if k, ok := strings.CutSuffix(key, "-asc"); ok {
+			key = k
+		}
+		if _, ok := sortKeys[key]; !ok {
+			return nil, fmt.Errorf("unknown sort key %q (want %s)", key, strings.Join(sortKeyNames(), ", "))
+		}
+		spec = append(spec, sortTerm{key: key, desc: desc})
+	}
+	return spec, nil
+}
+
+func sortKeyNames() []string {
+	names := make([]string, 0, len(sortCycle))
+	for _, t := range sortCycle {
+		names = append(names, t.key)
+	}
+	return names
+}
+
+func (spec sortSpec) String() string {
+	if len(spec) == 0 {
+		return "number"
+	}
+	parts := make([]string, len(spec))
+	for i, t := range spec {
+		parts[i] = t.key
+		if t.desc {
+			parts[i] = "-" + t.key
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// nextSort steps the primary key through sortCycle. Any secondary keys
+// from config are dropped; number always breaks ties.
+func nextSort(spec sortSpec) sortSpec {
+	primary := "number"
+	if len(spec) > 0 {
+		primary = spec[0].key
+	}
+	for i, t := range sortCycle {
+		if t.key == primary {
+			return sortSpec{sortCycle[(i+1)%len(sortCycle)]}
+		}
+	}
+	return sortSpec{sortCycle[0]}
+}
+
+// reverseSort flips the primary key's direction.
+func reverseSort(spec sortSpec) sortSpec {
+	if len(spec) == 0 {
+		spec = sortSpec{{key: "number"}}
+	}
+	out := append(sortSpec(nil), spec...)
+	out[0].desc = !out[0].desc
+	return out
+}
+
+func sortStatePath() string { return filepath.Join(cacheDir(), "sort.json") }
+
+// savedSort returns the sort last chosen in the TUI for a view, if any.
+func savedSort(view string) (sortSpec, bool) {
+	data, err := os.ReadFile(sortStatePath())
+	if err != nil {
+		return nil, false
+	}
+	var saved map[string]string
+	if json.Unmarshal(data, &saved) != nil {
+		return nil, false
+	}
+	s, ok := saved[view]
+	if !ok {
+		return nil, false
+	}
+	spec, err := parseSort(s)
+	return spec, err == nil
+}
+
+// saveSort remembers the sort chosen for a view across sessions.
+func saveSort(view string, spec sortSpec) {
+	saved := map[string]string{}
+	if data, err := os.ReadFile(sortStatePath()); err == nil {
+		json.Unmarshal(data, &saved)
+	}
+	saved[view] = spec.String()
+	if data, err := json.Marshal(saved); err == nil {
+		os.WriteFile(sortStatePath(), data, 0644)
+	}
+}
+
+// sortPRs sorts by spec, then by number and key so equal rows never swap
+// places between refreshes.
+func sortPRs(prs []PR, spec sortSpec) {
+	sort.SliceStable(prs, func(i, j int) bool {
+		for _, t := range spec {
+			c := sortKeys[t.key](prs[i], prs[j])
+			if t.desc {
+				c = -c
+			}
+			if c != 0 {
+				return c < 0
+			}
+		}
+		if c := cmp.Compare(prs[i].Number, prs[j].Number); c != 0 {
+			return c < 0
+		}
+		return prKey(prs[i]) < prKey(prs[j])
+	})
+}
+
+// setSort changes the current view's order, keeping the cursor on the same
+// PR, and remembers it for next time.
+func (m *model) setSort(spec sortSpec) {
+	var selected string
+	if m.cursor < len(m.filtered) {
+		selected = prKey(m.filtered[m.cursor])
+	}
+	m.sort = spec
+	sortPRs(m.prs, spec)
+	m.applyFilter()
+	for i, pr := range m.filtered {
+		if prKey(pr) == selected {
+			m.cursor = i
+			break
+		}
+	}
+	if !demoMode {
+		saveSort(views[m.view].Name, spec)
+	}
+}
+
+// sortLabel describes the order in effect, for the footer.
+func (m model) sortLabel() string {
+	if spec := filterSort(m.filter); spec != nil {
+		return spec.String() + " (filter)"
+	}
+	return m.sort.String()
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSort(t *testing.T) {
+	tests := []struct{ in, want, err string }{
+		{in: "", want: "number"},
+		{in: "updated", want: "updated"},
+		{in: "-updated", want: "-updated"},
+		{in: "updated-desc", want: "-updated"},
+		{in: "size-asc", want: "size"},
+		{in: " -Created , repo ,", want: "-created,repo"},
+		{in: "age", err: `unknown sort key "age"`},
+		{in: "repo,-nope", err: `unknown sort key "nope"`},
+	}
+	for _, tt := range tests {
+		spec, err := parseSort(tt.in)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("parseSort(%q) = %v, want an error containing %q", tt.in, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || spec.String() != tt.want {
+			t.Errorf("parseSort(%q) = %q, %v, want %q", tt.in, spec.String(), err, tt.want)
+		}
+	}
+}
+
+func TestSortPRs(t *testing.T) {
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	pr := func(n int, repo, author string, size, age int) PR {
+		p := testPR(n, "")
+		p.Repository.Name = repo
+		p.Author.Login = author
+		p.Additions = size
+		p.UpdatedAt = day.AddDate(0, 0, -age)
+		return p
+	}
+	prs := []PR{
+		pr(4, "web", "bob", 10, 1),
+		pr(1, "api", "Carol", 500, 3),
+		pr(3, "api", "alice", 10, 2),
+		pr(2, "web", "alice", 80, 1),
+	}
+
+	tests := []struct {
+		spec string
+		want []int
+	}{
+		{"", []int{1, 2, 3, 4}},
+		{"-number", []int{4, 3, 2, 1}},
+		{"-updated", []int{2, 4, 3, 1}}, // ties fall back to number
+		{"size", []int{3, 4, 2, 1}},
+		{"repo,-size", []int{1, 3, 2, 4}},
+		{"author", []int{2, 3, 4, 1}}, // case-insensitive
+	}
+	for _, tt := range tests {
+		spec, err := parseSort(tt.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := append([]PR(nil), prs...)
+		sortPRs(got, spec)
+		var numbers []int
+		for _, p := range got {
+			numbers = append(numbers, p.Number)
+		}
+		if fmt.Sprint(numbers) != fmt.Sprint(tt.want) {
+			t.Errorf("sortPRs(%q) = %v, want %v", tt.spec, numbers, tt.want)
+		}
+	}
+}
+
+func TestNextAndReverseSort(t *testing.T) {
+	spec := sortSpec(nil)
+	var seen []string
+	for range sortCycle {
+		spec = nextSort(spec)
+		seen = append(seen, spec.String())
+	}
+	if got := strings.Join(seen, " "); got != "-updated -created -size repo author review ci number" {
+		t.Errorf("nextSort cycle: %s", got)
+	}
+	if got := nextSort(sortSpec{{key: "repo"}, {key: "size"}}).String(); got != "author" {
+		t.Errorf("nextSort(repo,size) = %s, want author with the secondary key dropped", got)
+	}
+	if got := reverseSort(nil).String(); got != "-number" {
+		t.Errorf("reverseSort(nil) = %s", got)
+	}
+	orig := sortSpec{{key: "size", desc: true}, {key: "repo"}}
+	if got := reverseSort(orig).String(); got != "size,repo" || orig.String() != "-size,repo" {
+		t.Errorf("reverseSort(-size,repo) = %s (original now %s)", got, orig)
+	}
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Themes: every color sup draws with is a role in palette, and the styles
+// below are rebuilt from the chosen palette by applyTheme. The default
+// theme adapts to the terminal background; the others are fixed. NO_COLOR
+// picks no-color unless a theme is chosen explicitly.
+
+// palette assigns a color to each role. Selected variants are used on the
+// cursor row, where they're also bold.
+type palette struct {
+	Accent, Caret                lipgloss.TerminalColor // titles and the active tab; the » cursor
+	Text, TextSelected           lipgloss.TerminalColor
+	Dim, Muted, MutedSelected    lipgloss.TerminalColor // rules and authors; help text and drafts
+	Approved, ApprovedSelected   lipgloss.TerminalColor
+	Denied, DeniedSelected       lipgloss.TerminalColor
+	Review, ReviewSelected       lipgloss.TerminalColor
+	Commented, CommentedSelected lipgloss.TerminalColor
+	Open, OpenSelected           lipgloss.TerminalColor
+	Added, AddedSelected         lipgloss.TerminalColor
+	Deleted, DeletedSelected     lipgloss.TerminalColor
+	Match, Prompt, Busy          lipgloss.TerminalColor // filter matches; filter line and keys; spinners
+	Branch, BranchSelected       lipgloss.TerminalColor
+	Section, Code, Quote         lipgloss.TerminalColor // headings and labels; markdown code and quotes
+}
+
+// darkPalette is sup's original look, for dark terminals.
+var darkPalette = palette{
+	Accent: lipgloss.Color("205"), Caret: lipgloss.Color("33"),
+	Text: lipgloss.Color("252"), TextSelected: lipgloss.Color("15"),
+	Dim: lipgloss.Color("240"), Muted: lipgloss.Color("241"), MutedSelected: lipgloss.Color("250"),
+	Approved: lipgloss.Color("78"), ApprovedSelected: lipgloss.Color("114"),
+	Denied: lipgloss.Color("196"), DeniedSelected: lipgloss.Color("203"),
+	Review: lipgloss.Color("214"), ReviewSelected: lipgloss.Color("221"),
+	Commented: lipgloss.Color("117"), CommentedSelected: lipgloss.Color("159"),
+	Open: lipgloss.Color("39"), OpenSelected: lipgloss.Color("81"),
+	Added: lipgloss.Color("78"), AddedSelected: lipgloss.Color("114"),
+	Deleted: lipgloss.Color("196"), DeletedSelected: lipgloss.Color("203"),
+	Match: lipgloss.Color("214"), Prompt: lipgloss.Color("220"), Busy: lipgloss.Color("214"),
+	Branch: lipgloss.Color("141"), BranchSelected: lipgloss.Color("183"),
+	Section: lipgloss.Color("141"), Code: lipgloss.Color("180"), Quote: lipgloss.Color("245"),
+}
+
+// lightPalette darkens every role enough to read on a white background.
+var lightPalette = palette{
+	Accent: lipgloss.Color("162"), Caret: lipgloss.Color("25"),
+	Text: lipgloss.Color("236"), TextSelected: lipgloss.Color("16"),
+	Dim: lipgloss.Color("244"), Muted: lipgloss.Color("245"), MutedSelected: lipgloss.Color("240"),
+	Approved: lipgloss.Color("28"), ApprovedSelected: lipgloss.Color("22"),
+	Denied: lipgloss.Color("160"), DeniedSelected: lipgloss.Color("124"),
+	Review: lipgloss.Color("166"), ReviewSelected: lipgloss.Color("130"),
+	Commented: lipgloss.Color("31"), CommentedSelected: lipgloss.Color("24"),
+	Open: lipgloss.Color("25"), OpenSelected: lipgloss.Color("19"),
+	Added: lipgloss.Color("28"), AddedSelected: lipgloss.Color("22"),
+	Deleted: lipgloss.Color("160"), DeletedSelected: lipgloss.Color("124"),
+	Match: lipgloss.Color("166"), Prompt: lipgloss.Color("130"), Busy: lipgloss.Color("166"),
+	Branch: lipgloss.Color("91"), BranchSelected: lipgloss.Color("54"),
+	Section: lipgloss.Color("91"), Code: lipgloss.Color("94"), Quote: lipgloss.Color("242"),
+}
+
+// highContrastDark uses the terminal's bright ANSI colors at full
+// strength and no greys darker than 250.
+var highContrastDark = palette{
+	Accent: lipgloss.Color("13"), Caret: lipgloss.Color("14"),
+	Text: lipgloss.Color("15"), TextSelected: lipgloss.Color("15"),
+	Dim: lipgloss.Color("250"), Muted: lipgloss.Color("250"), MutedSelected: lipgloss.Color("15"),
+	Approved: lipgloss.Color("10"), ApprovedSelected: lipgloss.Color("10"),
+	Denied: lipgloss.Color("9"), DeniedSelected: lipgloss.Color("9"),
+	Review: lipgloss.Color("11"), ReviewSelected: lipgloss.Color("11"),
+	Commented: lipgloss.Color("14"), CommentedSelected: lipgloss.Color("14"),
+	Open: lipgloss.Color("12"), OpenSelected: lipgloss.Color("12"),
+	Added: lipgloss.Color("10"), AddedSelected: lipgloss.Color("10"),
+	Deleted: lipgloss.Color("9"), DeletedSelected: lipgloss.Color("9"),
+	Match: lipgloss.Color("11"), Prompt: lipgloss.Color("11"), Busy: lipgloss.Color("11"),
+	Branch: lipgloss.Color("13"), BranchSelected: lipgloss.Color("13"),
+	Section: lipgloss.Color("13"), Code: lipgloss.Color("11"), Quote: lipgloss.Color("250"),
+}
+
+var highContrastLight = palette{
+	Accent: lipgloss.Color("90"), Caret: lipgloss.Color("18"),
+	Text: lipgloss.Color("16"), TextSelected: lipgloss.Color("16"),
+	Dim: lipgloss.Color("238"), Muted: lipgloss.Color("238"), MutedSelected: lipgloss.Color("16"),
+	Approved: lipgloss.Color("22"), ApprovedSelected: lipgloss.Color("22"),
+	Denied: lipgloss.Color("88"), DeniedSelected: lipgloss.Color("88"),
+	Review: lipgloss.Color("94"), ReviewSelected: lipgloss.Color("94"),
+	Commented: lipgloss.Color("18"), CommentedSelected: lipgloss.Color("18"),
+	Open: lipgloss.Color("19"), OpenSelected: lipgloss.Color("19"),
+	Added: lipgloss.Color("22"), AddedSelected: lipgloss.Color("22"),
+	Deleted: lipgloss.Color("88"), DeletedSelected: lipgloss.Color("88"),
+	Match: lipgloss.Color("90"), Prompt: lipgloss.Color("94"), Busy: lipgloss.Color("94"),
+	Branch: lipgloss.Color("53"), BranchSelected: lipgloss.Color("53"),
+	Section: lipgloss.Color("53"), Code: lipgloss.Color("94"), Quote: lipgloss.Color("238"),
+}
+
+// colorblindPalette swaps the red/green status and diff colors for the
+// Okabe-Ito set (blue for good, orange for bad), which stays distinct under
+// the common forms of color blindness.
+func colorblindPalette(base palette, light bool) palette {
+	p := base
+	if light {
+		p.Approved, p.ApprovedSelected = lipgloss.Color("#0072B2"), lipgloss.Color("#005082")
+		p.Denied, p.DeniedSelected = lipgloss.Color("#D55E00"), lipgloss.Color("#A04600")
+		p.Review, p.ReviewSelected = lipgloss.Color("#996F00"), lipgloss.Color("#7A5900")
+		p.Commented, p.CommentedSelected = lipgloss.Color("#AA4499"), lipgloss.Color("#882E77")
+		p.Open, p.OpenSelected = lipgloss.Color("#007A5A"), lipgloss.Color("#005C44")
+	} else {
+		p.Approved, p.ApprovedSelected = lipgloss.Color("#56B4E9"), lipgloss.Color("#9ED3F2")
+		p.Denied, p.DeniedSelected = lipgloss.Color("#E69F00"), lipgloss.Color("#F5C242")
+		p.Review, p.ReviewSelected = lipgloss.Color("#F0E442"), lipgloss.Color("#F7EF8A")
+		p.Commented, p.CommentedSelected = lipgloss.Color("#CC79A7"), lipgloss.Color("#E0A9C8")
+		p.Open, p.OpenSelected = lipgloss.Color("#009E73"), lipgloss.Color("#3CC39A")
+	}
+	p.Added, p.AddedSelected = p.Approved, p.ApprovedSelected
+	p.Deleted, p.DeletedSelected = p.Denied, p.DeniedSelected
+	return p
+}
+
+// eachColor calls f with a pointer to every role in p.
+func eachColor(p *palette, f func(i int, color *lipgloss.TerminalColor)) {
+	v := reflect.ValueOf(p).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		f(i, v.Field(i).Addr().Interface().(*lipgloss.TerminalColor))
+	}
+}
+
+// adaptive pairs two fixed palettes into one that follows the terminal's
+// background.
+func adaptive(light, dark palette) palette {
+	var lights []lipgloss.TerminalColor
+	eachColor(&light, func(_ int, color *lipgloss.TerminalColor) { lights = append(lights, *color) })
+	p := dark
+	eachColor(&p, func(i int, color *lipgloss.TerminalColor) {
+		*color = lipgloss.AdaptiveColor{
+			Light: string(lights[i].(lipgloss.Color)),
+			Dark:  string((*color).(lipgloss.Color)),
+		}
+	})
+	return p
+}
+
+func noColorPalette() palette {
+	var p palette
+	eachColor(&p, func(_ int, color *lipgloss.TerminalColor) { *color = lipgloss.NoColor{} })
+	return p
+}
+
+var themes = map[string]palette{
+	"default":         adaptive(lightPalette, darkPalette),
+	"dark":            darkPalette,
+	"light":           lightPalette,
+	"high-contrast":   adaptive(highContrastLight, highContrastDark),
+	"colorblind-safe": adaptive(colorblindPalette(lightPalette, true), colorblindPalette(darkPalette, false)),
+	"no-color":        noColorPalette(),
+}
+
+func themeNames() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// themeName is the theme in effect: --theme, SUP_THEME or the config's
+// theme, else no-color when NO_COLOR is set, else default.
+func themeName() string {
+	if opts.Theme != "" {
+		return strings.ToLower(opts.Theme)
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return "no-color"
+	}
+	return "default"
+}
+
+// setTheme applies the named theme.
+func setTheme(name string) error {
+	p, ok := themes[name]
+	if !ok {
+		return fmt.Errorf("unknown theme %q (want one of %s)", name, strings.Join(themeNames(), ", "))
+	}
+	// Adaptive colors ask the terminal for its background on first use;
+	// do that now, before Bubble Tea takes over stdin.
+	lipgloss.HasDarkBackground()
+	applyTheme(p)
+	return nil
+}
+
+var (
+	titleStyle, caretStyle, selectedStyle  lipgloss.Style
+	normalStyle, selectedNormalStyle       lipgloss.Style
+	dimStyle, helpStyle, authorStyle       lipgloss.Style
+	draftStyle, selectedDraftStyle         lipgloss.Style
+	approvedStyle, selectedApprovedStyle   lipgloss.Style
+	changesRequestedStyle                  lipgloss.Style
+	selectedChangesRequestedStyle          lipgloss.Style
+	reviewRequestedStyle                   lipgloss.Style
+	selectedReviewRequestedStyle           lipgloss.Style
+	commentedStyle, selectedCommentedStyle lipgloss.Style
+	openStyle, selectedOpenStyle           lipgloss.Style
+	additionsStyle, selectedAdditionsStyle lipgloss.Style
+	deletionsStyle, selectedDeletionsStyle lipgloss.Style
+	matchStyle, filterStyle, keyStyle      lipgloss.Style
+	loadingStyle                           lipgloss.Style
+	branchStyle, selectedBranchStyle       lipgloss.Style
+	sectionStyle, labelStyle               lipgloss.Style
+	codeStyle, quoteStyle                  lipgloss.Style
+	activeTabStyle, tabStyle               lipgloss.Style
+)
+
+func init() {
+	applyTheme(themes["default"])
+}
+
+// applyTheme rebuilds every style from p.
+func applyTheme(p palette) {
+	fg := func(color lipgloss.TerminalColor) lipgloss.Style {
+		return lipgloss.NewStyle().Foreground(color)
+	}
+	sel := func(color lipgloss.TerminalColor) lipgloss.Style {
+		return fg(color).Bold(true)
+	}
+
+	titleStyle = sel(p.Accent)
+	caretStyle = sel(p.Caret)
+	selectedStyle = lipgloss.NewStyle().Bold(true)
+	normalStyle, selectedNormalStyle = fg(p.Text), sel(p.TextSelected)
+	dimStyle, helpStyle, authorStyle = fg(p.Dim), fg(p.Muted), fg(p.Dim)
+	draftStyle, selectedDraftStyle = fg(p.Muted), sel(p.MutedSelected)
+	approvedStyle, selectedApprovedStyle = fg(p.Approved), sel(p.ApprovedSelected)
+	changesRequestedStyle, selectedChangesRequestedStyle = fg(p.Denied), sel(p.DeniedSelected)
+	reviewRequestedStyle, selectedReviewRequestedStyle = fg(p.Review), sel(p.ReviewSelected)
+	commentedStyle, selectedCommentedStyle = fg(p.Commented), sel(p.CommentedSelected)
+	openStyle, selectedOpenStyle = fg(p.Open), sel(p.OpenSelected)
+	additionsStyle, selectedAdditionsStyle = fg(p.Added), sel(p.AddedSelected)
+	deletionsStyle, selectedDeletionsStyle = fg(p.Deleted), sel(p.DeletedSelected)
+	matchStyle = sel(p.Match).Underline(true)
+	filterStyle, keyStyle = sel(p.Prompt), fg(p.Prompt)
+	loadingStyle = fg(p.Busy)
+	branchStyle, selectedBranchStyle = fg(p.Branch), sel(p.BranchSelected)
+	sectionStyle, labelStyle = sel(p.Section), fg(p.Section)
+	codeStyle, quoteStyle = fg(p.Code), fg(p.Quote).Italic(true)
+	activeTabStyle, tabStyle = sel(p.Accent), fg(p.Dim)
+}
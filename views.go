@@ -0,0 +1,206 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Views are named PR lists shown as tabs: the built-in one (your orgs, or
+// --mine) first, then each [[views]] table from the config file. Every view
+// keeps its own PRs, filter, cursor and cache for the session.
+
+var views []viewConfig
+
+// initViews builds the view list once flags and config are loaded.
+func initViews() {
+	builtin := viewConfig{Name: "All"}
+	if mineMode {
+		builtin.Name = "Mine"
+	}
+	views = append([]viewConfig{builtin}, cfg.Views...)
+}
+
+// startView is the index of the view the TUI opens on (--view, SUP_VIEW or
+// default_view), or -1 if there's no such view. "All" and "Mine" name the
+// built-in view whichever mode it's in.
+func startView() int {
+	switch strings.ToLower(opts.View) {
+	case "", "all", "mine":
+		return 0
+	}
+	return findView(opts.View)
+}
+
+// viewState is what a view leaves behind when another one is shown.
+type viewState struct {
+	visited    bool
+	refreshed  bool // a refresh has run to completion this session
+	prs        []PR
+	filterText string
+	cursor     int
+	sort       sortSpec
+}
+
+// findView returns the index of the view called name, or -1.
+func findView(name string) int {
+	for i, v := range views {
+		if strings.EqualFold(v.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// viewShards returns the searches backing view i.
+func viewShards(i int) []searchShard {
+	v := views[i]
+	if len(v.Search) == 0 {
+		return searchShards()
+	}
+	host := defaultHost()
+	if v.Host != "" {
+		host = normalizeHost(v.Host)
+	}
+	var s []searchShard
+	for _, q := range v.Search {
+		if !strings.Contains(q, "is:pr") {
+			q += " is:pr"
+		}
+		s = append(s, searchShard{Host: host, Query: q})
+	}
+	return s
+}
+
+var viewSlugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// viewSlug is a view name as it appears in file names: "My PRs" is
+// "my-prs". Config validation keeps it unique and non-empty.
+func viewSlug(name string) string {
+	return strings.Trim(viewSlugPattern.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// viewCachePath is the PR cache for a configured view, named after it. The
+// built-in view keeps the per-host caches.
+func viewCachePath(i int) string {
+	return filepath.Join(cacheDir(), "view-"+viewSlug(views[i].Name)+".json")
+}
+
+func loadViewCache(i int) []PR {
+	if i == 0 {
+		return loadCachedPRs()
+	}
+	data, err := os.ReadFile(viewCachePath(i))
+	if err != nil {
+		return nil
+	}
+	var prs []PR
+	if err := json.Unmarshal(data, &prs); err != nil {
+		return nil
+	}
+	return prs
+}
+
+func saveViewCache(i int, prs []PR) {
+	if i == 0 {
+		savePRsToCache(prs)
+		return
+	}
+	data, err := json.Marshal(prs)
+	if err != nil {
+		return
+	}
+	os.WriteFile(viewCachePath(i), data, 0644)
+}
+
+// enterView makes view i current, restoring its PRs, filter and cursor from
+// earlier in the session or, the first time, from its cache and config. The
+// view's own filter isn't part of filterText: applyFilter adds it, so it
+// can't be typed or cleared away. It reports whether the view still needs a
+// refresh.
+func (m *model) enterView(i int) bool {
+	m.view = i
+	st := &m.viewStates[i]
+	if !st.visited {
+		st.visited = true
+		st.sort = views[i].sort
+		if spec, ok := savedSort(views[i].Name); ok && !demoMode {
+			st.sort = spec
+		}
+		if !demoMode {
+			st.prs = loadViewCache(i)
+		}
+		sortPRs(st.prs, st.sort)
+	}
+	m.sort = st.sort
+	m.loading = st.prs == nil
+	m.prs = st.prs
+	if m.prs == nil {
+		m.prs = []PR{}
+	}
+	m.filterText = st.filterText
+	m.applyFilter()
+	m.cursor = min(st.cursor, max(len(m.filtered)-1, 0))
+	if m.loading {
+		m.visibleCount = 0
+	} else {
+		m.visibleCount = len(m.filtered)
+	}
+	return !st.refreshed
+}
+
+// withViewFilter ANDs a view's filter with the typed one. Either may be nil.
+// The typed one goes first, so its sort: term beats the view's.
+func withViewFilter(view, typed filterExpr) filterExpr {
+	switch {
+	case view == nil:
+		return typed
+	case typed == nil:
+		return view
+	}
+	return andExpr{typed, view}
+}
+
+// switchView stashes the current view's state and shows view i, starting
+// its first refresh if it hasn't had one.
+func (m *model) switchView(i int) tea.Cmd {
+	if i < 0 || i >= len(views) || i == m.view {
+		return nil
+	}
+	st := &m.viewStates[m.view]
+	st.prs, st.filterText, st.cursor, st.sort = m.prs, m.filterText, m.cursor, m.sort
+	// Pages still in flight belong to the view we're leaving; bumping the ID
+	// drops them, and that view refreshes again when it's next shown.
+	m.refreshID++
+	m.refreshing = false
+	m.filterMode = false
+	if !m.enterView(i) {
+		return nil
+	}
+	m.refreshing = true
+	return m.startRefresh()
+}
+
+// tabsView renders the view tabs, or "" when there's only the built-in view.
+func (m model) tabsView() string {
+	if len(views) < 2 {
+		return ""
+	}
+	var tabs []string
+	for i, v := range views {
+		label := v.Name
+		if i < 9 {
+			label = string(rune('1'+i)) + " " + label
+		}
+		if i == m.view {
+			tabs = append(tabs, activeTabStyle.Render("["+label+"]"))
+		} else {
+			tabs = append(tabs, tabStyle.Render(" "+label+" "))
+		}
+	}
+	return "  " + strings.Join(tabs, " ")
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Worktree checkouts give each PR its own directory beside the main clone,
+// so checking one out never touches work in progress there.
+
+// defaultWorktreeDir is the worktree layout when worktree_dir isn't set.
+const defaultWorktreeDir = "{repo}-worktrees/pr-{number}"
+
+// worktreeFields are the placeholders worktree_dir may use.
+var worktreeFields = []string{"{owner}", "{repo}", "{number}", "{branch}"}
+
+// checkoutModes are the values of --checkout, SUP_CHECKOUT and checkout.
+var checkoutModes = []string{"branch", "worktree"}
+
+// checkoutMode is how Enter and `sup checkout` check PRs out: "branch"
+// switches the main clone to the PR, "worktree" adds a worktree for it.
+func checkoutMode() string {
+	if opts.Checkout == "" {
+		return "branch"
+	}
+	return strings.ToLower(opts.Checkout)
+}
+
+func validCheckoutMode(mode string) bool {
+	for _, m := range checkoutModes {
+		if strings.EqualFold(mode, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateWorktreeDir checks a worktree_dir layout. It has to tell PRs
+// apart, so it needs {number} or {branch}.
+func validateWorktreeDir(layout string) error {
+	rest := layout
+	for _, f := range worktreeFields {
+		rest = strings.ReplaceAll(rest, f, "")
+	}
+	if i := strings.Index(rest, "{"); i >= 0 {
+		field, _, _ := strings.Cut(rest[i:], "}")
+		return fmt.Errorf("unknown placeholder %s} (want %s)", field, strings.Join(worktreeFields, ", "))
+	}
+	if !strings.Contains(layout, "{number}") && !strings.Contains(layout, "{branch}") {
+		return fmt.Errorf("%q needs {number} or {branch}, or every PR gets the same directory", layout)
+	}
+	return nil
+}
+
+// worktreePath is where a PR's worktree goes. Relative layouts are beside
+// the main clone; ~ and absolute paths are taken as they are.
+func worktreePath(repoPath string, pr PR) string {
+	layout := cfg.WorktreeDir
+	if layout == "" {
+		layout = defaultWorktreeDir
+	}
+	path := strings.NewReplacer(
+		"{owner}", pr.Repository.Owner.Login,
+		"{repo}", pr.Repository.Name,
+		"{number}", strconv.Itoa(pr.Number),
+		"{branch}", strings.ReplaceAll(pr.HeadRefName, "/", "-"),
+	).Replace(layout)
+	if path == "~" || strings.HasPrefix(path, "~/") || filepath.IsAbs(path) {
+		return expandPath(path)
+	}
+	return filepath.Join(filepath.Dir(repoPath), path)
+}
+
+// worktree is one entry of `git worktree list --porcelain`.
+type worktree struct {
+	Path     string
+	Head     string
+	Branch   string // short name; "" when detached
+	Bare     bool
+	Locked   bool
+	Prunable bool // its directory is gone
+}
+
+// listWorktrees lists a clone's worktrees, the main one first.
+func listWorktrees(repoPath string) ([]worktree, error) {
+	cmd := exec.Command("git", "worktree", "list", "--porcelain")
+	cmd.Dir = repoPath
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git worktree list in %s: %v", repoPath, err)
+	}
+	var wts []worktree
+	for _, line := range strings.Split(string(out), "\n") {
+		field, value, _ := strings.Cut(line, " ")
+		if field == "worktree" {
+			wts = append(wts, worktree{Path: value})
+			continue
+		}
+		if len(wts) == 0 {
+			continue
+		}
+		wt := &wts[len(wts)-1]
+		switch field {
+		case "HEAD":
+			wt.Head = value
+		case "branch":
+			wt.Branch = strings.TrimPrefix(value, "refs/heads/")
+		case "bare":
+			wt.Bare = true
+		case "locked":
+			wt.Locked = true
+		case "prunable":
+			wt.Prunable = true
+		}
+	}
+	return wts, nil
+}
+
+// runGit runs git in dir, returning its output or its stderr as the error.
+func runGit(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("git %s: %v", args[0], err)
+	}
+	return out, nil
+}
+
+// uncommittedChanges lists the changes in a worktree as `git status
+// --porcelain` lines: changes to tracked files, and untracked files too
+// when untracked is set.
+func uncommittedChanges(dir string, untracked bool) ([]string, error) {
+	mode := "--untracked-files=no"
+	if untracked {
+		mode = "--untracked-files=normal"
+	}
+	cmd := exec.Command("git", "status", "--porcelain", mode)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git status in %s: %v", dir, err)
+	}
+	var changes []string
+	for _, line := range strings.Split(strings.TrimRight(string(out), "\n"), "\n") {
+		if line != "" {
+			changes = append(changes, line)
+		}
+	}
+	return changes, nil
+}
+
+// addPRWorktree creates a worktree for pr and checks the PR out in it with
+// gh, which sets the branch up to track the PR's head (forks included).
+// The worktree is removed again if the checkout fails.
+func addPRWorktree(repoPath string, pr PR) (string, error) {
+	path := worktreePath(repoPath, pr)
+	if _, err := os.Stat(path); err == nil {
+		return "", fmt.Errorf("%s already exists but isn't on branch '%s'; remove it (git worktree remove) or change worktree_dir", path, pr.HeadRefName)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+
+	fmt.Printf("Creating worktree for PR #%d at %s...\n", pr.Number, path)
+	add := exec.Command("git", "worktree", "add", "--detach", path)
+	add.Dir = repoPath
+	add.Stdout = os.Stdout
+	add.Stderr = os.Stderr
+	if err := add.Run(); err != nil {
+		return "", fmt.Errorf("git worktree add failed: %v", err)
+	}
+
+	co := exec.Command("gh", "pr", "checkout", strconv.Itoa(pr.Number))
+	co.Dir = path
+	co.Stdout = os.Stdout
+	co.Stderr = os.Stderr
+	if err := co.Run(); err != nil {
+		rm := exec.Command("git", "worktree", "remove", "--force", path)
+		rm.Dir = repoPath
+		rm.Run()
+		return "", fmt.Errorf("gh pr checkout failed: %v", err)
+	}
+	return path, nil
+}